@@ -3,14 +3,15 @@ package main
 import (
 	"GamesProject/internal/cli"
 	"GamesProject/internal/db"
+	"GamesProject/internal/jobs"
+	"GamesProject/internal/mailer"
 	"GamesProject/internal/utils"
+	"context"
+	"fmt"
+	"os"
 )
 
 func main() {
-	utils.ClearTerminal()
-
-	utils.InitSignalHandler()
-
 	pool, err := db.Connect()
 	if err != nil {
 		panic(err)
@@ -18,6 +19,26 @@ func main() {
 	db.Pool = pool
 	defer pool.Close()
 
+	mailer.Default = mailer.FromEnv()
+
+	// Non-interactive subcommands, e.g. "myapp catalog import ..."
+	if len(os.Args) > 1 {
+		if err := cli.RunCommand(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			pool.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	utils.ClearTerminal()
+
+	utils.InitSignalHandler()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobs.Start(ctx)
+
 	cli.ProgramStart()
 
 }
@@ -1,3 +1,97 @@
+create table public.audit_log (
+  auditid bigserial not null,
+  actorauthid integer null,
+  action character varying(50) not null,
+  targettype character varying(30) null,
+  targetid integer null,
+  before jsonb null,
+  after jsonb null,
+  created_at timestamp without time zone not null default CURRENT_TIMESTAMP,
+  constraint audit_log_pkey primary key (auditid),
+  constraint audit_log_actorauthid_fkey foreign KEY (actorauthid) references userauth (authid)
+) TABLESPACE pg_default;
+
+create index audit_log_created_at_idx on public.audit_log using btree (created_at) TABLESPACE pg_default;
+
+create or replace function public.audit_log_append_only () RETURNS trigger LANGUAGE plpgsql as $$
+begin
+  raise exception 'audit_log is append-only';
+end;
+$$;
+
+create trigger audit_log_append_only BEFORE
+update
+or delete on public.audit_log for EACH row
+execute FUNCTION public.audit_log_append_only ();
+
+create table public.auth_tokens (
+  tokenid serial not null,
+  authid integer not null,
+  purpose character varying(20) not null,
+  tokenhash character(64) not null,
+  newemail character varying(150) null,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  expires_at timestamp without time zone not null,
+  used_at timestamp without time zone null,
+  attempts integer not null default 0,
+  constraint auth_tokens_pkey primary key (tokenid),
+  constraint auth_tokens_authid_fkey foreign KEY (authid) references userauth (authid),
+  constraint auth_tokens_purpose_check check (
+    (
+      (purpose)::text = any (
+        (
+          array[
+            'email_change'::character varying,
+            'verify_email'::character varying,
+            'password_reset'::character varying
+          ]
+        )::text[]
+      )
+    )
+  )
+) TABLESPACE pg_default;
+
+create index auth_tokens_authid_purpose_idx on public.auth_tokens using btree (authid, purpose) TABLESPACE pg_default;
+
+create table public.bundleitems (
+  bundleid integer not null,
+  gameid integer not null,
+  constraint bundleitems_pkey primary key (bundleid, gameid),
+  constraint bundleitems_bundleid_fkey foreign KEY (bundleid) references bundles (bundleid),
+  constraint bundleitems_gameid_fkey foreign KEY (gameid) references games (gameid)
+) TABLESPACE pg_default;
+
+create table public.bundles (
+  bundleid serial not null,
+  developerid integer not null,
+  title character varying(200) not null,
+  discountpercent integer not null default 0,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  deleted_at timestamp without time zone null,
+  constraint bundles_pkey primary key (bundleid),
+  constraint bundles_developerid_fkey foreign KEY (developerid) references developers (developerid),
+  constraint bundles_discountpercent_check check ((discountpercent >= 0) and (discountpercent < 100))
+) TABLESPACE pg_default;
+
+create table public.currencies (
+  currencycode character(3) not null,
+  symbol character varying(5) not null,
+  exchangerate numeric(12, 6) not null,
+  updated_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  constraint currencies_pkey primary key (currencycode),
+  constraint currencies_exchangerate_check check ((exchangerate > (0)::numeric))
+) TABLESPACE pg_default;
+
+create table public.customer_recommendations (
+  customerid integer not null,
+  gameid integer not null,
+  score numeric(10, 2) not null,
+  computed_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  constraint customer_recommendations_pkey primary key (customerid, gameid),
+  constraint customer_recommendations_customerid_fkey foreign KEY (customerid) references customers (customerid),
+  constraint customer_recommendations_gameid_fkey foreign KEY (gameid) references games (gameid)
+) TABLESPACE pg_default;
+
 create table public.customers (
   customerid serial not null,
   authid integer not null,
@@ -6,14 +100,18 @@ create table public.customers (
   email character varying(150) not null,
   address text null,
   phone character varying(20) null,
+  currencycode character(3) null,
   created_at timestamp without time zone null default CURRENT_TIMESTAMP,
   deleted_at timestamp without time zone null,
   constraint customers_pkey primary key (customerid),
   constraint customers_authid_key unique (authid),
   constraint customers_email_key unique (email),
-  constraint customers_authid_fkey foreign KEY (authid) references userauth (authid)
+  constraint customers_authid_fkey foreign KEY (authid) references userauth (authid),
+  constraint customers_currencycode_fkey foreign KEY (currencycode) references currencies (currencycode)
 ) TABLESPACE pg_default;
 
+create unique index customers_username_key on public.customers using btree (lower((username)::text)) TABLESPACE pg_default;
+
 create table public.developers (
   developerid serial not null,
   developername character varying(150) not null,
@@ -25,6 +123,39 @@ create table public.developers (
   constraint fk_developers_auth foreign KEY (authid) references userauth (authid)
 ) TABLESPACE pg_default;
 
+create table public.discounts (
+  discountid serial not null,
+  developerid integer not null,
+  gameid integer null,
+  percentoff integer not null,
+  startsat timestamp without time zone not null,
+  endsat timestamp without time zone not null,
+  activated_at timestamp without time zone null,
+  concluded_at timestamp without time zone null,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  deleted_at timestamp without time zone null,
+  constraint discounts_pkey primary key (discountid),
+  constraint discounts_developerid_fkey foreign KEY (developerid) references developers (developerid),
+  constraint discounts_gameid_fkey foreign KEY (gameid) references games (gameid),
+  constraint discounts_percentoff_check check ((percentoff > 0) and (percentoff <= 100)),
+  constraint discounts_period_check check (endsat > startsat)
+) TABLESPACE pg_default;
+
+create table public.entitlements (
+  entitlementid serial not null,
+  customerid integer not null,
+  gameid integer not null,
+  orderitemid integer not null,
+  unlocked_at timestamp without time zone null,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  deleted_at timestamp without time zone null,
+  constraint entitlements_pkey primary key (entitlementid),
+  constraint entitlements_orderitemid_key unique (orderitemid),
+  constraint entitlements_customerid_fkey foreign KEY (customerid) references customers (customerid),
+  constraint entitlements_gameid_fkey foreign KEY (gameid) references games (gameid),
+  constraint entitlements_orderitemid_fkey foreign KEY (orderitemid) references orderitems (orderitemid)
+) TABLESPACE pg_default;
+
 create table public.gamegenres (
   gameid integer not null,
   genreid integer not null,
@@ -35,25 +166,176 @@ create table public.gamegenres (
   constraint gamegenres_genreid_fkey foreign KEY (genreid) references genres (genreid)
 ) TABLESPACE pg_default;
 
+create table public.gametags (
+  gameid integer not null,
+  tagid integer not null,
+  authid integer not null,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  constraint gametags_pkey primary key (gameid, tagid, authid),
+  constraint gametags_gameid_fkey foreign KEY (gameid) references games (gameid),
+  constraint gametags_tagid_fkey foreign KEY (tagid) references tags (tagid),
+  constraint gametags_authid_fkey foreign KEY (authid) references userauth (authid)
+) TABLESPACE pg_default;
+
+create table public.game_copurchases (
+  gameid integer not null,
+  relatedgameid integer not null,
+  score integer not null,
+  computed_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  constraint game_copurchases_pkey primary key (gameid, relatedgameid),
+  constraint game_copurchases_gameid_fkey foreign KEY (gameid) references games (gameid),
+  constraint game_copurchases_relatedgameid_fkey foreign KEY (relatedgameid) references games (gameid)
+) TABLESPACE pg_default;
+
+create table public.game_price_history (
+  historyid serial not null,
+  gameid integer not null,
+  price numeric(10, 2) not null,
+  baseprice numeric(10, 2) not null,
+  reason character varying(30) not null,
+  recorded_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  constraint game_price_history_pkey primary key (historyid),
+  constraint game_price_history_gameid_fkey foreign KEY (gameid) references games (gameid)
+) TABLESPACE pg_default;
+
+create table public.game_prices (
+  gameid integer not null,
+  currencycode character(3) not null,
+  price numeric(10, 2) not null,
+  updated_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  constraint game_prices_pkey primary key (gameid, currencycode),
+  constraint game_prices_gameid_fkey foreign KEY (gameid) references games (gameid),
+  constraint game_prices_currencycode_fkey foreign KEY (currencycode) references currencies (currencycode),
+  constraint game_prices_price_check check ((price >= (0)::numeric))
+) TABLESPACE pg_default;
+
 create table public.games (
   gameid serial not null,
   developerid integer not null,
   title character varying(200) not null,
   price numeric(10, 2) not null,
   releasedate date null,
+  released_at timestamp without time zone null,
+  basegameid integer null,
+  sku character varying(64) null,
+  maxperorder integer not null default 10,
+  hidden_at timestamp without time zone null,
   created_at timestamp without time zone null default CURRENT_TIMESTAMP,
   deleted_at timestamp without time zone null,
   constraint games_pkey primary key (gameid),
-  constraint games_developerid_fkey foreign KEY (developerid) references developers (developerid)
+  constraint games_maxperorder_check check ((maxperorder > 0)),
+  constraint games_developerid_fkey foreign KEY (developerid) references developers (developerid),
+  constraint games_basegameid_fkey foreign KEY (basegameid) references games (gameid)
 ) TABLESPACE pg_default;
 
+-- removed games keep their SKU without blocking it for a new listing
+create unique index games_developerid_sku_key on public.games using btree (developerid, sku) TABLESPACE pg_default
+where
+  (deleted_at is null);
+
+create or replace function public.games_default_sku () RETURNS trigger LANGUAGE plpgsql as $$
+begin
+  if new.sku is null then
+    new.sku := 'GAME-' || new.gameid;
+  end if;
+  return new;
+end;
+$$;
+
+create trigger games_default_sku BEFORE insert on public.games for EACH row
+execute FUNCTION public.games_default_sku ();
+
 create table public.genres (
   genreid serial not null,
   genrename character varying(100) not null,
+  parentid integer null,
   created_at timestamp without time zone null default CURRENT_TIMESTAMP,
   deleted_at timestamp without time zone null,
   constraint genres_pkey primary key (genreid),
-  constraint genres_genrename_key unique (genrename)
+  constraint genres_genrename_key unique (genrename),
+  constraint genres_parentid_fkey foreign KEY (parentid) references genres (genreid)
+) TABLESPACE pg_default;
+
+create table public.gifts (
+  giftid serial not null,
+  orderid integer not null,
+  sendercustomerid integer not null,
+  recipientcustomerid integer not null,
+  message character varying(500) null,
+  status character varying(20) not null default 'pending'::character varying,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  responded_at timestamp without time zone null,
+  constraint gifts_pkey primary key (giftid),
+  constraint gifts_orderid_key unique (orderid),
+  constraint gifts_orderid_fkey foreign KEY (orderid) references orders (orderid),
+  constraint gifts_sendercustomerid_fkey foreign KEY (sendercustomerid) references customers (customerid),
+  constraint gifts_recipientcustomerid_fkey foreign KEY (recipientcustomerid) references customers (customerid),
+  constraint gifts_status_check check (
+    (
+      (status)::text = any (
+        (
+          array[
+            'pending'::character varying,
+            'accepted'::character varying,
+            'declined'::character varying
+          ]
+        )::text[]
+      )
+    )
+  )
+) TABLESPACE pg_default;
+
+create table public.invoices (
+  invoiceid serial not null,
+  invoiceseq integer not null,
+  invoicenumber character varying(20) not null,
+  orderid integer not null,
+  customerid integer not null,
+  body text not null,
+  issued_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  constraint invoices_pkey primary key (invoiceid),
+  constraint invoices_invoiceseq_key unique (invoiceseq),
+  constraint invoices_invoicenumber_key unique (invoicenumber),
+  constraint invoices_orderid_key unique (orderid),
+  constraint invoices_orderid_fkey foreign KEY (orderid) references orders (orderid),
+  constraint invoices_customerid_fkey foreign KEY (customerid) references customers (customerid)
+) TABLESPACE pg_default;
+
+create or replace function public.invoices_immutable () RETURNS trigger LANGUAGE plpgsql as $$
+begin
+  raise exception 'invoices cannot be changed once issued';
+end;
+$$;
+
+create trigger invoices_immutable BEFORE
+update
+or delete on public.invoices for EACH row
+execute FUNCTION public.invoices_immutable ();
+
+create table public.login_attempts (
+  attemptid bigserial not null,
+  email character varying(150) not null,
+  authid integer null,
+  source character varying(100) not null,
+  success boolean not null,
+  reason character varying(30) null,
+  created_at timestamp without time zone not null default CURRENT_TIMESTAMP,
+  constraint login_attempts_pkey primary key (attemptid),
+  constraint login_attempts_authid_fkey foreign KEY (authid) references userauth (authid)
+) TABLESPACE pg_default;
+
+create index login_attempts_email_idx on public.login_attempts using btree (lower((email)::text), created_at) TABLESPACE pg_default;
+
+create index login_attempts_source_idx on public.login_attempts using btree (source, created_at) TABLESPACE pg_default;
+
+create table public.notifications (
+  notificationid serial not null,
+  authid integer not null,
+  message text not null,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  read_at timestamp without time zone null,
+  constraint notifications_pkey primary key (notificationid),
+  constraint notifications_authid_fkey foreign KEY (authid) references userauth (authid)
 ) TABLESPACE pg_default;
 
 create table public.orderitems (
@@ -62,9 +344,13 @@ create table public.orderitems (
   gameid integer not null,
   quantity integer not null default 1,
   priceatpurchase numeric(10, 2) not null,
+  listprice numeric(10, 2) null,
+  bundleid integer null,
+  taxamount numeric(10, 2) not null default 0,
   created_at timestamp without time zone null default CURRENT_TIMESTAMP,
   deleted_at timestamp without time zone null,
   constraint orderitems_pkey primary key (orderitemid),
+  constraint orderitems_bundleid_fkey foreign KEY (bundleid) references bundles (bundleid),
   constraint orderitems_gameid_fkey foreign KEY (gameid) references games (gameid),
   constraint orderitems_orderid_fkey foreign KEY (orderid) references orders (orderid)
 ) TABLESPACE pg_default;
@@ -74,10 +360,34 @@ create table public.orders (
   customerid integer not null,
   orderdate timestamp without time zone null default CURRENT_TIMESTAMP,
   totalprice numeric(10, 2) null,
+  currencycode character(3) null,
+  exchangerate numeric(12, 6) not null default 1,
+  taxtotal numeric(10, 2) not null default 0,
+  taxrate numeric(5, 2) not null default 0,
+  taxinclusive boolean not null default false,
+  status character varying(20) not null default 'cart'::character varying,
+  checkedout_at timestamp without time zone null,
+  cancelled_at timestamp without time zone null,
   created_at timestamp without time zone null default CURRENT_TIMESTAMP,
   deleted_at timestamp without time zone null,
   constraint orders_pkey primary key (orderid),
-  constraint orders_customerid_fkey foreign KEY (customerid) references customers (customerid)
+  constraint orders_status_check check (
+    (
+      (status)::text = any (
+        (
+          array[
+            'cart'::character varying,
+            'pending'::character varying,
+            'paid'::character varying,
+            'cancelled'::character varying,
+            'refunded'::character varying
+          ]
+        )::text[]
+      )
+    )
+  ),
+  constraint orders_customerid_fkey foreign KEY (customerid) references customers (customerid),
+  constraint orders_currencycode_fkey foreign KEY (currencycode) references currencies (currencycode)
 ) TABLESPACE pg_default;
 
 create table public.paymentlogs (
@@ -109,11 +419,115 @@ create table public.payments (
   constraint payments_paymentmethodid_fkey foreign KEY (paymentmethodid) references paymentmethods (paymentmethodid)
 ) TABLESPACE pg_default;
 
+create table public.review_helpful (
+  reviewid integer not null,
+  customerid integer not null,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  constraint review_helpful_pkey primary key (reviewid, customerid),
+  constraint review_helpful_reviewid_fkey foreign KEY (reviewid) references reviews (reviewid),
+  constraint review_helpful_customerid_fkey foreign KEY (customerid) references customers (customerid)
+) TABLESPACE pg_default;
+
+create table public.reviews (
+  reviewid serial not null,
+  customerid integer not null,
+  gameid integer not null,
+  rating integer not null,
+  body text null,
+  developerreply text null,
+  replied_at timestamp without time zone null,
+  hidden_at timestamp without time zone null,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  updated_at timestamp without time zone null,
+  deleted_at timestamp without time zone null,
+  constraint reviews_pkey primary key (reviewid),
+  constraint reviews_customerid_gameid_key unique (customerid, gameid),
+  constraint reviews_customerid_fkey foreign KEY (customerid) references customers (customerid),
+  constraint reviews_gameid_fkey foreign KEY (gameid) references games (gameid),
+  constraint reviews_rating_check check ((rating >= 1) and (rating <= 5))
+) TABLESPACE pg_default;
+
+create table public.suspension_games (
+  suspensionid integer not null,
+  gameid integer not null,
+  constraint suspension_games_pkey primary key (suspensionid, gameid),
+  constraint suspension_games_suspensionid_fkey foreign KEY (suspensionid) references suspensions (suspensionid),
+  constraint suspension_games_gameid_fkey foreign KEY (gameid) references games (gameid)
+) TABLESPACE pg_default;
+
+create table public.suspensions (
+  suspensionid serial not null,
+  authid integer not null,
+  reason character varying(500) not null,
+  suspendedby integer null,
+  created_at timestamp without time zone not null default CURRENT_TIMESTAMP,
+  expires_at timestamp without time zone null,
+  hidegames boolean not null default false,
+  lifted_at timestamp without time zone null,
+  liftedby integer null,
+  constraint suspensions_pkey primary key (suspensionid),
+  constraint suspensions_authid_fkey foreign KEY (authid) references userauth (authid),
+  constraint suspensions_suspendedby_fkey foreign KEY (suspendedby) references userauth (authid),
+  constraint suspensions_liftedby_fkey foreign KEY (liftedby) references userauth (authid)
+) TABLESPACE pg_default;
+
+create unique index suspensions_active_key on public.suspensions using btree (authid) TABLESPACE pg_default
+where
+  (lifted_at is null);
+
+create table public.tags (
+  tagid serial not null,
+  tagname character varying(30) not null,
+  blacklisted_at timestamp without time zone null,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  constraint tags_pkey primary key (tagid),
+  constraint tags_tagname_key unique (tagname)
+) TABLESPACE pg_default;
+
+create table public.tax_rates (
+  taxrateid serial not null,
+  country character varying(60) not null,
+  region character varying(60) null,
+  taxname character varying(30) not null default 'Tax'::character varying,
+  ratepercent numeric(5, 2) not null,
+  constraint tax_rates_pkey primary key (taxrateid),
+  constraint tax_rates_ratepercent_check check (
+    (
+      (ratepercent >= (0)::numeric)
+      and (ratepercent <= (100)::numeric)
+    )
+  )
+) TABLESPACE pg_default;
+
+create unique index tax_rates_country_region_key on public.tax_rates using btree (lower((country)::text), COALESCE(lower((region)::text), ''::text)) TABLESPACE pg_default;
+
+create table public.totp_recovery_codes (
+  codeid serial not null,
+  authid integer not null,
+  codehash character(64) not null,
+  used_at timestamp without time zone null,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  constraint totp_recovery_codes_pkey primary key (codeid),
+  constraint totp_recovery_codes_authid_fkey foreign KEY (authid) references userauth (authid)
+) TABLESPACE pg_default;
+
+create table public.user_totp (
+  authid integer not null,
+  secret character varying(64) not null,
+  enabled_at timestamp without time zone null,
+  last_step bigint not null default 0,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  constraint user_totp_pkey primary key (authid),
+  constraint user_totp_authid_fkey foreign KEY (authid) references userauth (authid)
+) TABLESPACE pg_default;
+
 create table public.userauth (
   authid serial not null,
   email character varying(150) not null,
   passwordhash text not null,
   role character varying(20) not null,
+  email_verified_at timestamp without time zone null,
+  locked_until timestamp without time zone null,
   created_at timestamp without time zone null default CURRENT_TIMESTAMP,
   deleted_at timestamp without time zone null,
   constraint userauth_pkey primary key (authid),
@@ -131,4 +545,14 @@ create table public.userauth (
       )
     )
   )
-) TABLESPACE pg_default;
\ No newline at end of file
+) TABLESPACE pg_default;
+
+create table public.wishlists (
+  customerid integer not null,
+  gameid integer not null,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  deleted_at timestamp without time zone null,
+  constraint wishlists_pkey primary key (customerid, gameid),
+  constraint wishlists_customerid_fkey foreign KEY (customerid) references customers (customerid),
+  constraint wishlists_gameid_fkey foreign KEY (gameid) references games (gameid)
+) TABLESPACE pg_default;
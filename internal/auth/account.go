@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/mailer"
+	"GamesProject/internal/repository"
+	"GamesProject/internal/utils"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// How long each kind of emailed code stays valid
+const (
+	emailChangeTTL   = 30 * time.Minute
+	verifyEmailTTL   = 24 * time.Hour
+	passwordResetTTL = 30 * time.Minute
+)
+
+// ErrEmailNotVerified is returned by Login when verification is required and still pending
+var ErrEmailNotVerified = errors.New("email address is not verified")
+
+// VerificationRequired reports whether REQUIRE_EMAIL_VERIFICATION is on
+func VerificationRequired() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("REQUIRE_EMAIL_VERIFICATION"))) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// newCode returns an 8-digit one-time code and the hash that gets stored
+func newCode() (string, string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(100_000_000))
+	if err != nil {
+		return "", "", err
+	}
+	code := fmt.Sprintf("%08d", n.Int64())
+	return code, hashToken(code), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
+// sendCode creates a token for purpose and mails its code to email
+func sendCode(ctx context.Context, authID int, purpose, email string, newEmail *string, ttl time.Duration, subject, intro string) error {
+	code, hash, err := newCode()
+	if err != nil {
+		return err
+	}
+	if err := repository.CreateAuthToken(ctx, db.Pool, authID, purpose, hash, newEmail, ttl); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("%s\n\n    %s\n\nThe code expires in %s and can be used once.\nIf you didn't ask for this, you can ignore this email.\n",
+		intro, code, ttl)
+	return mailer.Send(ctx, email, subject, body)
+}
+
+func checkPassword(ctx context.Context, authID int, password string) error {
+	hash, err := repository.GetPasswordHash(ctx, db.Pool, authID)
+	if err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return errors.New("current password is incorrect")
+	}
+	return nil
+}
+
+func ChangePassword(ctx context.Context, authID int, current, newPassword string) error {
+	if err := checkPassword(ctx, authID, current); err != nil {
+		return err
+	}
+	if newPassword == current {
+		return errors.New("new password must be different from the current one")
+	}
+	email, name := accountNames(ctx, authID)
+	if err := ValidatePassword(newPassword, email, name); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return repository.UpdatePasswordHash(ctx, db.Pool, authID, string(hash))
+}
+
+/*
+RequestEmailChange – the address only changes once the code sent to it is
+confirmed, so a typo can't lock anyone out of their account
+*/
+func RequestEmailChange(ctx context.Context, authID int, password, newEmail string) error {
+	newEmail = strings.TrimSpace(newEmail)
+	if !utils.ValidEmail(newEmail) {
+		return errors.New("invalid email format")
+	}
+	if err := checkPassword(ctx, authID, password); err != nil {
+		return err
+	}
+
+	inUse, err := repository.EmailInUse(ctx, db.Pool, newEmail)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return errors.New("email is already in use")
+	}
+
+	return sendCode(ctx, authID, "email_change", newEmail, &newEmail, emailChangeTTL,
+		"Confirm your new email address",
+		"Enter this code under My Account to finish changing your email:")
+}
+
+func PendingEmailChange(ctx context.Context, authID int) (*string, error) {
+	return repository.GetPendingEmailChange(ctx, db.Pool, authID)
+}
+
+func ConfirmEmailChange(ctx context.Context, authID int, code string) error {
+	email, err := repository.ConfirmEmailChange(ctx, db.Pool, authID, hashToken(code))
+	if err != nil {
+		return err
+	}
+
+	if CurrentUser != nil && CurrentUser.AuthID == authID {
+		CurrentUser.Email = email
+	}
+	return nil
+}
+
+// SendVerification mails a fresh verification code; unknown addresses are ignored
+func SendVerification(ctx context.Context, email string) error {
+	authID, err := repository.GetAuthIDByEmail(ctx, db.Pool, email)
+	if err != nil {
+		return nil
+	}
+	return sendCode(ctx, authID, "verify_email", email, nil, verifyEmailTTL,
+		"Verify your email address",
+		"Welcome! Enter this code when you log in to verify your email:")
+}
+
+func VerifyEmail(ctx context.Context, email, code string) error {
+	authID, err := repository.GetAuthIDByEmail(ctx, db.Pool, email)
+	if err != nil {
+		return repository.ErrInvalidToken
+	}
+	return repository.VerifyEmail(ctx, db.Pool, authID, hashToken(code))
+}
+
+/*
+RequestPasswordReset – always succeeds for well-formed input so the
+response doesn't reveal which emails have accounts
+*/
+func RequestPasswordReset(ctx context.Context, email string) error {
+	authID, err := repository.GetAuthIDByEmail(ctx, db.Pool, email)
+	if err != nil {
+		return nil
+	}
+	return sendCode(ctx, authID, "password_reset", email, nil, passwordResetTTL,
+		"Reset your password",
+		"Someone asked to reset the password for this account. Enter this code to choose a new one:")
+}
+
+func ResetPassword(ctx context.Context, email, code, newPassword string) error {
+	authID, err := repository.GetAuthIDByEmail(ctx, db.Pool, email)
+	if err != nil {
+		return repository.ErrInvalidToken
+	}
+
+	accountEmail, name := accountNames(ctx, authID)
+	if err := ValidatePassword(newPassword, accountEmail, name); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return repository.ResetPassword(ctx, db.Pool, authID, hashToken(code), string(hash))
+}
@@ -5,6 +5,8 @@ import (
 	"GamesProject/internal/repository"
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"golang.org/x/crypto/bcrypt"
@@ -12,25 +14,100 @@ import (
 
 var CurrentUser *repository.UserAuth = nil
 
+/*
+Login – throttled per account and per source (see throttle.go); every attempt is
+recorded in login_attempts
+*/
 func Login(ctx context.Context, email, password string) error {
 	user, err := repository.GetUserAuthByEmail(ctx, db.Pool, email)
 	if err != nil {
+		user = nil
+	}
+
+	if err := checkThrottle(ctx, email, user); err != nil {
+		return err
+	}
+
+	if user == nil {
+		if err := checkSuspended(ctx, email, password); err != nil {
+			return err
+		}
+		recordFailure(ctx, email, nil, "unknown email")
 		return errors.New("invalid email or password")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		recordFailure(ctx, email, user, "wrong password")
 		return errors.New("invalid email or password")
 	}
 
+	if VerificationRequired() && user.VerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+
+	twoFactor, err := TOTPEnabled(ctx, user.AuthID)
+	if err != nil {
+		return err
+	}
+	if twoFactor {
+		pending.user, pending.tries = user, 0
+		return ErrSecondFactor
+	}
+
+	recordSuccess(ctx, user)
 	CurrentUser = user
 	return nil
 }
 
+// SuspendedError is returned by Login for a suspended account once its password checks out
+type SuspendedError struct {
+	Reason string
+	Until  *time.Time // nil when permanent
+}
+
+func (e *SuspendedError) Error() string {
+	if e.Until == nil {
+		return "your account is suspended. Reason: " + e.Reason
+	}
+	return fmt.Sprintf("your account is suspended until %s. Reason: %s", e.Until.Format("2006-01-02 15:04"), e.Reason)
+}
+
+// checkSuspended only reveals the reason to someone who knows the password
+func checkSuspended(ctx context.Context, email, password string) error {
+	hash, s, err := repository.GetSuspensionForLogin(ctx, db.Pool, email)
+	if err != nil || s == nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return nil
+	}
+
+	reason := "suspended"
+	_ = repository.RecordLoginAttempt(ctx, db.Pool, email, &s.AuthID, sourceOf(ctx), false, &reason)
+	return &SuspendedError{Reason: s.Reason, Until: s.ExpiresAt}
+}
+
 func Logout() {
 	CurrentUser = nil
+	pending.user = nil
+}
+
+// ErrVerificationNotSent means registration succeeded but the first verification email failed
+var ErrVerificationNotSent = errors.New("account created, but the verification email could not be sent")
+
+// sendWelcomeVerification mails the first verification code; the account
+// stays usable for a resend from the login screen if this fails
+func sendWelcomeVerification(ctx context.Context, email string) error {
+	if err := SendVerification(ctx, email); err != nil {
+		return fmt.Errorf("%w: %w", ErrVerificationNotSent, err)
+	}
+	return nil
 }
 
 func Register(ctx context.Context, email, password, username string) error {
+	if err := ValidatePassword(password, email, username); err != nil {
+		return err
+	}
 
 	// Hash password
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -52,10 +129,17 @@ func Register(ctx context.Context, email, password, username string) error {
 	}
 
 	// Commit transaction
-	return tx.Commit(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	return sendWelcomeVerification(ctx, email)
 }
 
 func RegisterForAdmin(ctx context.Context, email, password string) error {
+	if err := ValidatePassword(password, email, ""); err != nil {
+		return err
+	}
 
 	// Hash password
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -77,10 +161,21 @@ func RegisterForAdmin(ctx context.Context, email, password string) error {
 	}
 
 	// Commit transaction
-	return tx.Commit(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	return sendWelcomeVerification(ctx, email)
 }
 
-func RegisterForDeveloper(ctx context.Context, email, password, devName string) error {
+/*
+RegisterForDeveloper creates a developer account. created, if set, runs in the
+same transaction with the new account's auth ID, e.g. to audit the creation.
+*/
+func RegisterForDeveloper(ctx context.Context, email, password, devName string, created func(tx pgx.Tx, authID int) error) error {
+	if err := ValidatePassword(password, email, devName); err != nil {
+		return err
+	}
 
 	// Hash password
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -96,11 +191,20 @@ func RegisterForDeveloper(ctx context.Context, email, password, devName string)
 	defer tx.Rollback(ctx)
 
 	// Perform the registration inside the transaction
-	err = repository.RegisterDeveloper(ctx, tx, email, string(hash), devName)
+	authID, err := repository.RegisterDeveloper(ctx, tx, email, string(hash), devName)
 	if err != nil {
 		return err
 	}
+	if created != nil {
+		if err := created(tx, authID); err != nil {
+			return err
+		}
+	}
 
 	// Commit transaction
-	return tx.Commit(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	return sendWelcomeVerification(ctx, email)
 }
@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// bcrypt ignores everything past 72 bytes, so longer passwords are refused
+const bcryptMaxBytes = 72
+
+/*
+PasswordPolicy comes from the environment:
+  - PASSWORD_MIN_LENGTH (default 8)
+  - PASSWORD_MIN_CLASSES: how many of lowercase, uppercase, digits and symbols (default 3)
+  - BREACHED_PASSWORDS_FILE: SHA-1 hash list to check against (off when unset)
+*/
+type PasswordPolicy struct {
+	MinLength    int
+	MinClasses   int
+	BreachedList string
+}
+
+func CurrentPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:    envInt("PASSWORD_MIN_LENGTH", 8),
+		MinClasses:   min(envInt("PASSWORD_MIN_CLASSES", 3), 4),
+		BreachedList: os.Getenv("BREACHED_PASSWORDS_FILE"),
+	}
+}
+
+// Hint describes the rules for prompts
+func (p PasswordPolicy) Hint() string {
+	return fmt.Sprintf("At least %d characters, using %d of: lowercase, uppercase, digits, symbols.", p.MinLength, p.MinClasses)
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	n := 0
+	for _, b := range []bool{lower, upper, digit, symbol} {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
+// PolicyError is a password the policy rejects, as opposed to a failure to check it
+type PolicyError struct {
+	Reason string
+}
+
+func (e *PolicyError) Error() string {
+	return e.Reason
+}
+
+/*
+Validate checks password against the policy. email and name (username or
+developer name) may be empty; when given, the password may not contain them.
+*/
+func (p PasswordPolicy) Validate(password, email, name string) error {
+	var problems []string
+
+	if len([]rune(password)) < p.MinLength {
+		problems = append(problems, fmt.Sprintf("be at least %d characters", p.MinLength))
+	}
+	if len(password) > bcryptMaxBytes {
+		problems = append(problems, fmt.Sprintf("be at most %d bytes", bcryptMaxBytes))
+	}
+	if characterClasses(password) < p.MinClasses {
+		problems = append(problems, fmt.Sprintf("mix %d of lowercase, uppercase, digits and symbols", p.MinClasses))
+	}
+
+	lowered := strings.ToLower(password)
+	local, _, _ := strings.Cut(strings.ToLower(email), "@")
+	for _, part := range []string{local, strings.ToLower(name)} {
+		if len(part) >= 3 && strings.Contains(lowered, part) {
+			problems = append(problems, "not contain your email or username")
+			break
+		}
+	}
+
+	if len(problems) > 0 {
+		return &PolicyError{Reason: "password must " + strings.Join(problems, ", ")}
+	}
+
+	if p.BreachedList == "" {
+		return nil
+	}
+	breached, err := isBreached(p.BreachedList, password)
+	if err != nil {
+		return fmt.Errorf("cannot check password against breached list: %w", err)
+	}
+	if breached {
+		return &PolicyError{Reason: "this password has appeared in a data breach, choose another one"}
+	}
+	return nil
+}
+
+// ValidatePassword applies the current policy; CLI prompts call it to fail early
+func ValidatePassword(password, email, name string) error {
+	return CurrentPasswordPolicy().Validate(password, email, name)
+}
+
+/*
+isBreached looks the password up k-anonymity style: only the first 5 hex
+characters of its SHA-1 select which entries are read, and the remaining 35 are
+compared locally. path is either a directory of range files named by prefix
+(as served by the Pwned Passwords range API, lines "SUFFIX:COUNT"), or a single
+file of full "HASH:COUNT" lines sorted by hash (the "ordered by hash" download),
+which is binary-searched rather than read whole.
+*/
+func isBreached(path, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	suffixes, err := breachedRange(path, prefix)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range suffixes {
+		if s == suffix {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// breachedRange returns the hash suffixes listed under prefix
+func breachedRange(path, prefix string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return sortedFileRange(path, info.Size(), prefix)
+	}
+
+	file := filepath.Join(path, prefix)
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		file += ".txt"
+	}
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil // nothing breached under this prefix
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if entry := hashOf(sc.Text()); entry != "" {
+			out = append(out, entry)
+		}
+	}
+	return out, sc.Err()
+}
+
+// sortedFileRange binary-searches a hash-sorted "HASH:COUNT" file for the
+// first line at or after prefix, then reads only the lines under it
+func sortedFileRange(path string, size int64, prefix string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var searchErr error
+	off := sort.Search(int(size), func(off int) bool {
+		start, err := lineStart(f, int64(off))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		line, err := bufio.NewReader(io.NewSectionReader(f, start, size-start)).ReadString('\n')
+		if err != nil && err != io.EOF {
+			searchErr = err
+			return true
+		}
+		return line == "" || hashOf(line) >= prefix
+	})
+	if searchErr != nil {
+		return nil, searchErr
+	}
+
+	start, err := lineStart(f, int64(off))
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	sc := bufio.NewScanner(io.NewSectionReader(f, start, size-start))
+	for sc.Scan() {
+		entry := hashOf(sc.Text())
+		if entry == "" {
+			continue
+		}
+		if !strings.HasPrefix(entry, prefix) {
+			break
+		}
+		out = append(out, entry[len(prefix):])
+	}
+	return out, sc.Err()
+}
+
+// lineStart returns the offset of the first line starting at or after off
+func lineStart(f *os.File, off int64) (int64, error) {
+	if off == 0 {
+		return 0, nil
+	}
+	// step back one byte so a line starting exactly at off is kept
+	skipped, err := bufio.NewReader(io.NewSectionReader(f, off-1, math.MaxInt64)).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	return off - 1 + int64(len(skipped)), nil
+}
+
+// hashOf returns the upper-cased hash part of a "HASH:COUNT" line
+func hashOf(line string) string {
+	entry, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+	return strings.ToUpper(entry)
+}
+
+// accountNames returns the email and display name the policy compares against
+func accountNames(ctx context.Context, authID int) (string, string) {
+	a, err := repository.GetAccountByAuthID(ctx, db.Pool, authID)
+	if err != nil {
+		return "", ""
+	}
+	if a.DeveloperName != nil {
+		return a.Email, *a.DeveloperName
+	}
+	if username, _, err := repository.GetCustomerInfoByAuthID(ctx, db.Pool, authID); err == nil {
+		return a.Email, username
+	}
+	return a.Email, ""
+}
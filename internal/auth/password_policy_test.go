@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	p := PasswordPolicy{MinLength: 8, MinClasses: 3}
+
+	tests := []struct {
+		password, email, name string
+		problem               string // "" when the password is accepted
+	}{
+		{"Tr0ub4dor", "", "", ""},
+		{"Sh0rt!", "", "", "be at least 8 characters"},
+		{"alllowercase", "", "", "mix 3 of"},
+		{"lower1234", "", "", "mix 3 of"},
+		{"lower-1234", "", "", ""},
+		{"Ünïcödé-1", "", "", ""}, // length counts runes, not bytes
+		{"Aa1" + strings.Repeat("x", 70), "", "", "be at most 72 bytes"},
+		{"Johnny-2024", "johnny@example.com", "", "not contain your email or username"},
+		{"XXgamer42YY", "", "Gamer", "not contain your email or username"},
+		{"Jo-12345678", "jo@example.com", "jo", ""}, // parts shorter than 3 are ignored
+	}
+
+	for _, tt := range tests {
+		err := p.Validate(tt.password, tt.email, tt.name)
+		if tt.problem == "" {
+			if err != nil {
+				t.Errorf("Validate(%q) = %v, want nil", tt.password, err)
+			}
+			continue
+		}
+		var policyErr *PolicyError
+		if !errors.As(err, &policyErr) {
+			t.Errorf("Validate(%q) = %v, want a *PolicyError", tt.password, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.problem) {
+			t.Errorf("Validate(%q) = %q, want it to mention %q", tt.password, err, tt.problem)
+		}
+	}
+}
+
+func TestCharacterClasses(t *testing.T) {
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"abcDEF", 2},
+		{"abcDEF123", 3},
+		{"abcDEF123!", 4},
+		{"ÄÖ ü", 3}, // the space is a symbol
+	}
+
+	for _, tt := range tests {
+		if got := characterClasses(tt.password); got != tt.want {
+			t.Errorf("characterClasses(%q) = %d, want %d", tt.password, got, tt.want)
+		}
+	}
+}
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// breachedFixtures writes the same hash list in both supported layouts
+func breachedFixtures(t *testing.T, breached []string) (file, dir string) {
+	t.Helper()
+	root := t.TempDir()
+
+	var lines []string
+	for i, pw := range breached {
+		lines = append(lines, fmt.Sprintf("%s:%d", sha1Hex(pw), i+1))
+	}
+	// filler around the real entries so the search has something to skip
+	for i := range 200 {
+		lines = append(lines, fmt.Sprintf("%s:1", sha1Hex(fmt.Sprintf("filler-%d", i))))
+	}
+	sort.Strings(lines)
+
+	file = filepath.Join(root, "pwned.txt")
+	if err := os.WriteFile(file, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir = filepath.Join(root, "ranges")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ranges := map[string][]string{}
+	for _, l := range lines {
+		ranges[l[:5]] = append(ranges[l[:5]], l[5:])
+	}
+	for prefix, suffixes := range ranges {
+		if err := os.WriteFile(filepath.Join(dir, prefix), []byte(strings.Join(suffixes, "\n")), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return file, dir
+}
+
+func TestIsBreached(t *testing.T) {
+	breached := []string{"password", "123456", "Tr0ub4dor&3", "filler-0-again"}
+	file, dir := breachedFixtures(t, breached)
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"password", true},
+		{"123456", true},
+		{"Tr0ub4dor&3", true},
+		{"filler-0-again", true},
+		{"filler-17", true},
+		{"correct horse battery staple", false},
+		{"", false},
+	}
+
+	for _, layout := range []string{file, dir} {
+		for _, tt := range tests {
+			got, err := isBreached(layout, tt.password)
+			if err != nil {
+				t.Fatalf("isBreached(%s, %q): %v", filepath.Base(layout), tt.password, err)
+			}
+			if got != tt.want {
+				t.Errorf("isBreached(%s, %q) = %v, want %v", filepath.Base(layout), tt.password, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestValidateBreachedList(t *testing.T) {
+	file, _ := breachedFixtures(t, []string{"Tr0ub4dor&3"})
+	p := PasswordPolicy{MinLength: 8, MinClasses: 3, BreachedList: file}
+
+	var policyErr *PolicyError
+	if err := p.Validate("Tr0ub4dor&3", "", ""); !errors.As(err, &policyErr) {
+		t.Errorf("breached password: got %v, want a *PolicyError", err)
+	}
+	if err := p.Validate("Tr0ub4dor&4", "", ""); err != nil {
+		t.Errorf("unlisted password: got %v, want nil", err)
+	}
+
+	// an unreadable list is not a policy violation, so prompts stop retrying
+	p.BreachedList = filepath.Join(t.TempDir(), "missing.txt")
+	err := p.Validate("Tr0ub4dor&4", "", "")
+	if err == nil || errors.As(err, &policyErr) {
+		t.Errorf("missing list: got %v, want a non-policy error", err)
+	}
+}
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/mailer"
+	"GamesProject/internal/repository"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+type sourceKey struct{}
+
+// WithSource tags a login with where it came from (e.g. a client IP) for throttling
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// explicitSource is the source given by WithSource or LOGIN_SOURCE, if any
+func explicitSource(ctx context.Context) (string, bool) {
+	if s, ok := ctx.Value(sourceKey{}).(string); ok && s != "" {
+		return s, true
+	}
+	if s := os.Getenv("LOGIN_SOURCE"); s != "" {
+		return s, true
+	}
+	return "", false
+}
+
+// sourceOf labels login attempts: an explicit source, else this machine's hostname
+func sourceOf(ctx context.Context) string {
+	if s, ok := explicitSource(ctx); ok {
+		return s
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return "local"
+	}
+	return "local:" + host
+}
+
+func envInt(key string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return def
+}
+
+/*
+Throttling settings:
+  - LOGIN_BACKOFF_AFTER failures (default 3) start the delay at LOGIN_BACKOFF_BASE (1s),
+    doubling with each further failure up to LOGIN_BACKOFF_MAX (5m)
+  - LOGIN_LOCKOUT_THRESHOLD failures in a row (default 10) lock the account
+    for LOGIN_LOCKOUT_DURATION (15m)
+  - failures older than LOGIN_ATTEMPT_WINDOW (1h) are forgotten
+
+Backoff applies per account, and per source when one is set with WithSource or
+LOGIN_SOURCE.
+*/
+type throttleConfig struct {
+	backoffAfter int
+	backoffBase  time.Duration
+	backoffMax   time.Duration
+	lockAfter    int
+	lockFor      time.Duration
+	window       time.Duration
+}
+
+func loadThrottleConfig() throttleConfig {
+	return throttleConfig{
+		backoffAfter: envInt("LOGIN_BACKOFF_AFTER", 3),
+		backoffBase:  envDuration("LOGIN_BACKOFF_BASE", time.Second),
+		backoffMax:   envDuration("LOGIN_BACKOFF_MAX", 5*time.Minute),
+		lockAfter:    envInt("LOGIN_LOCKOUT_THRESHOLD", 10),
+		lockFor:      envDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		window:       envDuration("LOGIN_ATTEMPT_WINDOW", time.Hour),
+	}
+}
+
+// delay is how long to wait after the last failure in a streak of n
+func (c throttleConfig) delay(n int) time.Duration {
+	if n < c.backoffAfter {
+		return 0
+	}
+	d := c.backoffBase
+	for i := c.backoffAfter; i < n && d < c.backoffMax; i++ {
+		d *= 2
+	}
+	return min(d, c.backoffMax)
+}
+
+// checkBackoff refuses the attempt while the streak's delay hasn't passed
+func (c throttleConfig) checkBackoff(s repository.FailureStreak) error {
+	if s.Last == nil {
+		return nil
+	}
+	wait := time.Until(s.Last.Add(c.delay(s.Count)))
+	if wait > 0 {
+		return fmt.Errorf("too many failed attempts, try again in %s", wait.Round(time.Second))
+	}
+	return nil
+}
+
+// streakStart ignores failures before the window and before a lock that has
+// since ended, whether it ran out or was lifted by an admin or a password reset
+func (c throttleConfig) streakStart(user *repository.UserAuth) time.Time {
+	since := time.Now().Add(-c.window)
+	if user != nil && user.LockedUntil != nil && user.LockedUntil.After(since) {
+		since = *user.LockedUntil
+	}
+	return since
+}
+
+func lockedError(until time.Time) error {
+	return fmt.Errorf("account is locked after too many failed logins, try again after %s", until.Format("15:04"))
+}
+
+func recordSuccess(ctx context.Context, user *repository.UserAuth) {
+	_ = repository.RecordLoginAttempt(ctx, db.Pool, user.Email, &user.AuthID, sourceOf(ctx), true, nil)
+}
+
+/*
+recordFailure – logs the failure and locks the account once its streak reaches
+the threshold. The owner is told by notification and email the first time.
+*/
+func recordFailure(ctx context.Context, email string, user *repository.UserAuth, reason string) {
+	c := loadThrottleConfig()
+
+	var authID *int
+	if user != nil {
+		authID = &user.AuthID
+	}
+	_ = repository.RecordLoginAttempt(ctx, db.Pool, email, authID, sourceOf(ctx), false, &reason)
+
+	if user == nil {
+		return
+	}
+
+	streak, err := repository.EmailFailureStreak(ctx, db.Pool, user.Email, c.streakStart(user))
+	if err != nil || streak.Count < c.lockAfter {
+		return
+	}
+
+	until := time.Now().Add(c.lockFor)
+	locked, err := repository.LockAccount(ctx, db.Pool, user.AuthID, until)
+	if err != nil || !locked {
+		return
+	}
+
+	msg := fmt.Sprintf("Your account was locked until %s after %d failed login attempts. If this wasn't you, reset your password.",
+		until.Format("2006-01-02 15:04"), streak.Count)
+	_ = repository.CreateNotification(ctx, db.Pool, user.AuthID, msg)
+	_ = mailer.Send(ctx, user.Email, "Your account has been locked", msg+"\n")
+}
+
+// checkThrottle runs before the password is compared
+func checkThrottle(ctx context.Context, email string, user *repository.UserAuth) error {
+	c := loadThrottleConfig()
+
+	// the hostname fallback is shared by every user of this machine, so only
+	// an explicit source is throttled on its own
+	if source, ok := explicitSource(ctx); ok {
+		src, err := repository.SourceFailureStreak(ctx, db.Pool, source, time.Now().Add(-c.window))
+		if err != nil {
+			return err
+		}
+		if err := c.checkBackoff(src); err != nil {
+			return err
+		}
+	}
+
+	if user != nil && user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		return lockedError(*user.LockedUntil)
+	}
+
+	acct, err := repository.EmailFailureStreak(ctx, db.Pool, email, c.streakStart(user))
+	if err != nil {
+		return err
+	}
+	return c.checkBackoff(acct)
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"GamesProject/internal/repository"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testThrottle = throttleConfig{
+	backoffAfter: 3,
+	backoffBase:  time.Second,
+	backoffMax:   time.Minute,
+	lockAfter:    10,
+	lockFor:      15 * time.Minute,
+	window:       time.Hour,
+}
+
+func TestThrottleDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 4 * time.Second},
+		{8, 32 * time.Second},
+		{9, time.Minute}, // 64s is capped
+		{1000, time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := testThrottle.delay(tt.failures); got != tt.want {
+			t.Errorf("delay(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestCheckBackoff(t *testing.T) {
+	ago := func(d time.Duration) *time.Time {
+		at := time.Now().Add(-d)
+		return &at
+	}
+
+	tests := []struct {
+		name    string
+		streak  repository.FailureStreak
+		blocked bool
+	}{
+		{"no failures", repository.FailureStreak{}, false},
+		{"below the threshold", repository.FailureStreak{Count: 2, Last: ago(0)}, false},
+		{"delay still running", repository.FailureStreak{Count: 5, Last: ago(time.Second)}, true},
+		{"delay over", repository.FailureStreak{Count: 5, Last: ago(5 * time.Second)}, false},
+		{"capped delay running", repository.FailureStreak{Count: 50, Last: ago(30 * time.Second)}, true},
+	}
+
+	for _, tt := range tests {
+		err := testThrottle.checkBackoff(tt.streak)
+		if (err != nil) != tt.blocked {
+			t.Errorf("%s: checkBackoff = %v, want blocked %v", tt.name, err, tt.blocked)
+		}
+	}
+}
+
+func TestStreakStart(t *testing.T) {
+	now := time.Now()
+	recentUnlock := now.Add(-10 * time.Minute)
+	oldUnlock := now.Add(-2 * time.Hour)
+	unlockedNow := now // an admin unlock or password reset ends the lock now
+
+	tests := []struct {
+		name string
+		user *repository.UserAuth
+		want time.Time
+	}{
+		{"unknown account", nil, now.Add(-time.Hour)},
+		{"never locked", &repository.UserAuth{}, now.Add(-time.Hour)},
+		{"lock ended inside the window", &repository.UserAuth{LockedUntil: &recentUnlock}, recentUnlock},
+		{"lock ended before the window", &repository.UserAuth{LockedUntil: &oldUnlock}, now.Add(-time.Hour)},
+		{"unlocked early", &repository.UserAuth{LockedUntil: &unlockedNow}, now},
+	}
+
+	for _, tt := range tests {
+		got := testThrottle.streakStart(tt.user)
+		if d := got.Sub(tt.want); d < -time.Second || d > time.Second {
+			t.Errorf("%s: streakStart = %s, want about %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSourceIsOnlyExplicitWhenSet(t *testing.T) {
+	ctx := context.Background()
+
+	t.Setenv("LOGIN_SOURCE", "")
+	if s, ok := explicitSource(ctx); ok {
+		t.Errorf("no source set: explicitSource = %q, true; want false", s)
+	}
+	if s := sourceOf(ctx); s != "local" && !strings.HasPrefix(s, "local:") {
+		t.Errorf("sourceOf = %q, want the local fallback", s)
+	}
+
+	t.Setenv("LOGIN_SOURCE", "kiosk-3")
+	if s, ok := explicitSource(ctx); !ok || s != "kiosk-3" {
+		t.Errorf("LOGIN_SOURCE: explicitSource = %q, %v; want kiosk-3, true", s, ok)
+	}
+
+	ctx = WithSource(ctx, "203.0.113.7")
+	if s, ok := explicitSource(ctx); !ok || s != "203.0.113.7" {
+		t.Errorf("WithSource: explicitSource = %q, %v; want 203.0.113.7, true", s, ok)
+	}
+}
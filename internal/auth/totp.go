@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// RFC 6238 defaults, which every authenticator app understands
+const (
+	totpPeriod = 30
+	totpDigits = 6
+	totpSkew   = 1 // steps accepted either side of now, for clock drift
+
+	recoveryCodeCount  = 10
+	maxSecondFactorTry = 5
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ErrInvalidCode means a TOTP or recovery code didn't match
+var ErrInvalidCode = errors.New("invalid code")
+
+// ErrSecondFactor is returned by Login when the account needs a TOTP or recovery code
+var ErrSecondFactor = errors.New("two-factor code required")
+
+// pending holds a user who passed the password check but not the second factor yet
+var pending struct {
+	user  *repository.UserAuth
+	tries int
+}
+
+// hotp is RFC 4226 with HMAC-SHA1 and dynamic truncation
+func hotp(secret []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	off := sum[len(sum)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(sum[off:off+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, bin%1_000_000)
+}
+
+// matchTOTP returns the time step the code belongs to
+func matchTOTP(secret, code string, now time.Time) (int64, bool) {
+	key, err := b32.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	step := now.Unix() / totpPeriod
+	for d := int64(-totpSkew); d <= totpSkew; d++ {
+		if hmac.Equal([]byte(hotp(key, step+d)), []byte(code)) {
+			return step + d, true
+		}
+	}
+	return 0, false
+}
+
+func totpIssuer() string {
+	if name := os.Getenv("STORE_NAME"); name != "" {
+		return name
+	}
+	return "GamesProject Store"
+}
+
+// provisioningURI is the otpauth:// URI authenticator apps scan as a QR code
+func provisioningURI(account, secret string) string {
+	issuer := totpIssuer()
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(totpPeriod))
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+account) + "?" + q.Encode()
+}
+
+// TOTPRequired reports whether TOTP_REQUIRED_ROLES (comma separated) lists role
+func TOTPRequired(role string) bool {
+	for _, r := range strings.Split(os.Getenv("TOTP_REQUIRED_ROLES"), ",") {
+		if strings.EqualFold(strings.TrimSpace(r), role) {
+			return true
+		}
+	}
+	return false
+}
+
+// newRecoveryCodes returns codes like "k3j9d-x8q2m" with their hashes
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		s := strings.ToLower(b32.EncodeToString(buf))[:10]
+		codes[i] = s[:5] + "-" + s[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return hashToken(code)
+}
+
+/*
+checkSecondFactor – accepts a current TOTP code (each only once) or an unused
+recovery code, which is then burned
+*/
+func checkSecondFactor(ctx context.Context, authID int, code string) (bool, error) {
+	t, err := repository.GetTOTP(ctx, db.Pool, authID)
+	if err != nil {
+		return false, err
+	}
+	if t == nil || t.EnabledAt == nil {
+		return false, errors.New("two-factor authentication is not enabled")
+	}
+
+	if step, ok := matchTOTP(t.Secret, code, time.Now()); ok {
+		return repository.UseTOTPStep(ctx, db.Pool, authID, step)
+	}
+	return repository.UseRecoveryCode(ctx, db.Pool, authID, hashRecoveryCode(code))
+}
+
+// VerifySecondFactor completes a login that returned ErrSecondFactor
+func VerifySecondFactor(ctx context.Context, code string) error {
+	if pending.user == nil {
+		return errors.New("no login in progress")
+	}
+
+	ok, err := checkSecondFactor(ctx, pending.user.AuthID, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		recordFailure(ctx, pending.user.Email, pending.user, "wrong 2fa code")
+		pending.tries++
+		if pending.tries >= maxSecondFactorTry {
+			pending.user = nil
+			return errors.New("too many wrong codes, please log in again")
+		}
+		return ErrInvalidCode
+	}
+
+	recordSuccess(ctx, pending.user)
+	CurrentUser = pending.user
+	pending.user = nil
+	return nil
+}
+
+func TOTPEnabled(ctx context.Context, authID int) (bool, error) {
+	t, err := repository.GetTOTP(ctx, db.Pool, authID)
+	if err != nil {
+		return false, err
+	}
+	return t != nil && t.EnabledAt != nil, nil
+}
+
+// NeedsTOTPEnrollment is true when the logged-in role requires 2FA that isn't set up yet
+func NeedsTOTPEnrollment(ctx context.Context) (bool, error) {
+	if CurrentUser == nil || !TOTPRequired(CurrentUser.Role) {
+		return false, nil
+	}
+	enabled, err := TOTPEnabled(ctx, CurrentUser.AuthID)
+	return !enabled, err
+}
+
+func RecoveryCodesLeft(ctx context.Context, authID int) (int, error) {
+	return repository.CountRecoveryCodes(ctx, db.Pool, authID)
+}
+
+// BeginTOTPEnrollment returns the secret for manual entry and its provisioning URI
+func BeginTOTPEnrollment(ctx context.Context, authID int, account string) (string, string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	secret := b32.EncodeToString(buf)
+
+	if err := repository.SaveTOTPSecret(ctx, db.Pool, authID, secret); err != nil {
+		return "", "", err
+	}
+	return secret, provisioningURI(account, secret), nil
+}
+
+// ConfirmTOTPEnrollment turns 2FA on once the app shows a matching code; the recovery codes are shown only now
+func ConfirmTOTPEnrollment(ctx context.Context, authID int, code string) ([]string, error) {
+	t, err := repository.GetTOTP(ctx, db.Pool, authID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || t.EnabledAt != nil {
+		return nil, errors.New("no two-factor enrollment in progress")
+	}
+
+	step, ok := matchTOTP(t.Secret, code, time.Now())
+	if !ok {
+		return nil, errors.New("invalid code, check the time on your device")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := repository.EnableTOTP(ctx, db.Pool, authID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func RegenerateRecoveryCodes(ctx context.Context, authID int, code string) ([]string, error) {
+	ok, err := checkSecondFactor(ctx, authID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := repository.ReplaceRecoveryCodes(ctx, db.Pool, authID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTOTP needs a valid code and is refused for roles that must use 2FA
+func DisableTOTP(ctx context.Context, authID int, role, code string) error {
+	if TOTPRequired(role) {
+		return errors.New("two-factor authentication is required for your role")
+	}
+
+	ok, err := checkSecondFactor(ctx, authID, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidCode
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := repository.DeleteTOTP(ctx, tx, authID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+// the shared secret of the RFC 4226 and RFC 6238 (SHA-1) test vectors
+var rfcSecret = []byte("12345678901234567890")
+
+func TestHOTP(t *testing.T) {
+	// RFC 4226 appendix D
+	want := []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	}
+
+	for counter, code := range want {
+		if got := hotp(rfcSecret, int64(counter)); got != code {
+			t.Errorf("hotp(counter %d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestMatchTOTP(t *testing.T) {
+	secret := b32.EncodeToString(rfcSecret)
+
+	// RFC 6238 appendix B, SHA-1; the RFC uses 8 digits, we keep the last 6
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		now := time.Unix(tt.unix, 0)
+		step, ok := matchTOTP(secret, tt.code, now)
+		if !ok || step != tt.unix/totpPeriod {
+			t.Errorf("matchTOTP(%s at %d) = %d, %v; want %d, true", tt.code, tt.unix, step, ok, tt.unix/totpPeriod)
+		}
+	}
+}
+
+func TestMatchTOTPWindow(t *testing.T) {
+	secret := b32.EncodeToString(rfcSecret)
+	const code = "005924" // step 41152263, i.e. 1234567890 to 1234567919
+
+	tests := []struct {
+		name   string
+		unix   int64
+		code   string
+		wantOK bool
+	}{
+		{"same step", 1234567919, code, true},
+		{"one step late", 1234567920 + 29, code, true},
+		{"one step early", 1234567890 - 30, code, true},
+		{"two steps late", 1234567920 + 30, code, false},
+		{"spaces in the code", 1234567890, " 005 924 ", true},
+		{"wrong code", 1234567890, "005925", false},
+		{"too short", 1234567890, "05924", false},
+	}
+
+	for _, tt := range tests {
+		_, ok := matchTOTP(secret, tt.code, time.Unix(tt.unix, 0))
+		if ok != tt.wantOK {
+			t.Errorf("%s: matchTOTP = %v, want %v", tt.name, ok, tt.wantOK)
+		}
+	}
+
+	if _, ok := matchTOTP("not base32!", code, time.Unix(1234567890, 0)); ok {
+		t.Error("matchTOTP accepted an undecodable secret")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d", len(codes), len(hashes), recoveryCodeCount)
+	}
+
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+	seen := map[string]bool{}
+	for i, c := range codes {
+		if !format.MatchString(c) {
+			t.Errorf("code %q doesn't look like xxxxx-xxxxx", c)
+		}
+		if seen[c] {
+			t.Errorf("code %q repeated", c)
+		}
+		seen[c] = true
+		if hashes[i] != hashRecoveryCode(c) {
+			t.Errorf("hash %d doesn't belong to code %q", i, c)
+		}
+	}
+
+	// however the user types it, the same code hashes the same
+	for _, typed := range []string{"ABCDE-FGHIJ", "abcdefghij", " abcde fghij "} {
+		if hashRecoveryCode(typed) != hashRecoveryCode("abcde-fghij") {
+			t.Errorf("hashRecoveryCode(%q) differs from the canonical form", typed)
+		}
+	}
+}
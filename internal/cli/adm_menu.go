@@ -2,10 +2,13 @@ package cli
 
 import (
 	"GamesProject/internal/auth"
+	"GamesProject/internal/repository"
 	"GamesProject/internal/services"
 	"GamesProject/internal/utils"
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -19,6 +22,13 @@ func Adm_Menu() {
 		fmt.Println("[5] Transaction Report")
 		fmt.Println("[6] User List")
 		fmt.Println("[7] Developer List")
+		fmt.Println("[8] Review Moderation")
+		fmt.Println("[9] Manage Genres")
+		fmt.Println("[10] Tag Moderation")
+		fmt.Println("[11] Exchange Rates")
+		fmt.Println("[12] Tax Rates")
+		fmt.Println("[13] Two-Factor Authentication")
+		fmt.Println("[14] Audit Log")
 		fmt.Println("[0] Logout")
 
 		choice := utils.ReadChoice("=> ", 0, 387)
@@ -44,6 +54,27 @@ func Adm_Menu() {
 		case 7:
 			utils.ClearTerminal()
 			Adm_DeveloperList()
+		case 8:
+			utils.ClearTerminal()
+			Adm_ReviewModeration()
+		case 9:
+			utils.ClearTerminal()
+			Adm_ManageGenres()
+		case 10:
+			utils.ClearTerminal()
+			Adm_TagModeration()
+		case 11:
+			utils.ClearTerminal()
+			Adm_ExchangeRates()
+		case 12:
+			utils.ClearTerminal()
+			Adm_TaxRates()
+		case 13:
+			utils.ClearTerminal()
+			TwoFactorScreen()
+		case 14:
+			utils.ClearTerminal()
+			Adm_AuditLog()
 		case 387:
 			utils.ClearTerminal()
 			Adm_AllAccounts()
@@ -177,7 +208,7 @@ func Adm_RemoveGame(gameID int) bool {
 		return false
 	}
 
-	err := services.RemoveGame(ctx, gameID, auth.CurrentUser.Role, 0)
+	err := services.RemoveGame(ctx, auth.CurrentUser.AuthID, gameID, auth.CurrentUser.Role, 0)
 	if err != nil {
 		fmt.Println("Failed to remove game:", err)
 		time.Sleep(1000 * time.Millisecond)
@@ -195,8 +226,14 @@ func Adm_AddGenre() {
 	ctx := context.Background()
 
 	name := utils.ReadLine("Genre Name: ")
+	parent := utils.ReadInt("Parent Genre ID (0 = top-level): ")
 
-	err := services.AddGenre(ctx, name)
+	var parentID *int
+	if parent > 0 {
+		parentID = &parent
+	}
+
+	err := services.AddGenre(ctx, name, parentID)
 	if err != nil {
 		fmt.Println("Failed to add genre:", err)
 		time.Sleep(1000 * time.Millisecond)
@@ -274,13 +311,24 @@ func Adm_RemoveGenre() {
 
 		// Remove ID
 		if input.ID > 0 {
+			used, err := services.GenreUsageCount(ctx, input.ID)
+			if err != nil {
+				fmt.Println("Failed to check genre usage:", err)
+				time.Sleep(1000 * time.Millisecond)
+				utils.ClearTerminal()
+				continue
+			}
+			if used > 0 {
+				fmt.Printf("This genre is used by %d game(s) and will be hidden from them.\n", used)
+			}
+
 			if !utils.ReadConfirmation("Are you sure you want to remove this genre? (y/n): ") {
 				fmt.Println("Cancelled.")
 				utils.ClearTerminal()
 				continue
 			}
 
-			err := services.RemoveGenre(ctx, input.ID)
+			err = services.RemoveGenre(ctx, auth.CurrentUser.AuthID, input.ID)
 			if err != nil {
 				fmt.Println("Failed to remove genre:", err)
 				time.Sleep(1000 * time.Millisecond)
@@ -307,14 +355,14 @@ func Adm_AddDev() {
 
 	email := utils.ReadEmail("Email: ")
 
-	password, err := utils.ReadPasswordMasked("Password: ")
+	devName := utils.ReadLine("Developer Name: ")
+
+	password, err := ReadNewPassword("Password: ", email, devName)
 	if err != nil {
 		fmt.Println("Error reading password:", err)
 		return
 	}
 
-	devName := utils.ReadLine("Developer Name: ")
-
 	if !utils.ReadConfirmation("Create this developer account? (y/n): ") {
 		fmt.Println("Cancelled.")
 		time.Sleep(1000 * time.Millisecond)
@@ -322,7 +370,7 @@ func Adm_AddDev() {
 		return
 	}
 
-	err = auth.RegisterForDeveloper(ctx, email, password, devName)
+	err = services.RegisterDeveloper(ctx, auth.CurrentUser.AuthID, email, password, devName)
 	if err != nil {
 		fmt.Println("Failed to create developer:", err)
 		time.Sleep(1000 * time.Millisecond)
@@ -351,14 +399,22 @@ func Adm_Transaction() {
 		return
 	}
 
+	base := services.BaseCurrency(ctx)
+	var sum, taxSum float64
+
 	fmt.Println("\n=== TRANSACTION REPORT ===")
-	fmt.Println("|   Order ID   | Customer |  Total |       Date       |")
+	fmt.Printf("|   Order ID   | Customer |  Total |  Tax | Total (%s) | Tax (%s) |       Date       |\n", base.Code, base.Code)
 	for i, t := range list {
-		fmt.Printf("| [%d] Order #%d |  Cust %d  | $%.2f | %s |\n",
-			i+1, t.OrderID, t.CustomerID, t.TotalPrice,
+		fmt.Printf("| [%d] Order #%d |  Cust %d  | %.2f %s | %.2f | %s | %s | %s |\n",
+			i+1, t.OrderID, t.CustomerID, t.TotalPrice, t.CurrencyCode, t.TaxTotal,
+			services.FormatMoney(base, t.BaseTotal),
+			services.FormatMoney(base, t.BaseTaxTotal),
 			t.OrderDate.Format("2006-01-02 15:04"),
 		)
+		sum += t.BaseTotal
+		taxSum += t.BaseTaxTotal
 	}
+	fmt.Printf("Grand Total: %s (of which tax: %s)\n", services.FormatMoney(base, sum), services.FormatMoney(base, taxSum))
 
 	fmt.Println("[0] Back")
 	utils.ReadChoice("=> ", 0, 0)
@@ -667,6 +723,20 @@ func Adm_AccountDetail(authID int) {
 		fmt.Printf("Role      : %s\n", a.Role)
 		fmt.Printf("Created At: %s\n", a.CreatedAt.Format("2006-01-02 15:04:05"))
 		fmt.Printf("Status    : %s\n", status)
+		if s, _ := services.ActiveSuspension(ctx, a.AuthID); s != nil {
+			printSuspension(s)
+		}
+		locked := a.LockedUntil != nil && a.LockedUntil.After(time.Now())
+		if locked {
+			fmt.Printf("Locked    : until %s\n", a.LockedUntil.Format("2006-01-02 15:04:05"))
+		}
+
+		twoFactor, _ := auth.TOTPEnabled(ctx, a.AuthID)
+		if twoFactor {
+			fmt.Println("2FA       : ON")
+		} else {
+			fmt.Println("2FA       : OFF")
+		}
 
 		if a.Role == "developer" && a.DeveloperName != nil {
 			fmt.Printf("Developer ID   : %d\n", *a.DeveloperID)
@@ -676,28 +746,72 @@ func Adm_AccountDetail(authID int) {
 
 		fmt.Println("\n=== OPTIONS ===")
 
-		switch a.Role {
-		case "user":
-			if a.DeletedAt == nil {
-				fmt.Println("[1] Ban User")
-			} else {
-				fmt.Println("[1] Unban User")
-			}
-		case "developer":
-			fmt.Println("[1] (Cannot ban developer account)")
+		self := a.AuthID == auth.CurrentUser.AuthID
+		switch {
+		case self:
+			fmt.Println("[1] (Cannot suspend your own account)")
+		case a.DeletedAt == nil:
+			fmt.Println("[1] Suspend Account")
 		default:
-			fmt.Println("[1] (Cannot modify this account)")
+			fmt.Println("[1] Lift Suspension")
+		}
+
+		fmt.Println("[2] Reset Two-Factor Authentication")
+		fmt.Println("[3] Login History")
+		fmt.Println("[4] Suspension History")
+		maxChoice := 4
+		if locked {
+			fmt.Println("[5] Unlock Account")
+			maxChoice = 5
 		}
 
 		fmt.Println("[0] Back")
-		choice := utils.ReadChoice("=> ", 0, 1)
+		choice := utils.ReadChoice("=> ", 0, maxChoice)
 
 		if choice == 0 {
 			utils.ClearTerminal()
 			return
 		}
 
-		if a.Role != "user" {
+		if choice == 2 {
+			if utils.ReadConfirmation("Remove this account's 2FA and recovery codes? (y/n): ") {
+				if err := services.ResetUserTOTP(ctx, auth.CurrentUser.AuthID, a.AuthID); err != nil {
+					fmt.Println("Failed to reset 2FA:", err)
+				} else {
+					fmt.Println("2FA reset. The account can set it up again at next login.")
+				}
+			} else {
+				fmt.Println("Cancelled")
+			}
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			continue
+		}
+
+		if choice == 3 {
+			utils.ClearTerminal()
+			Adm_LoginHistory(a.AuthID, a.Email)
+			continue
+		}
+
+		if choice == 4 {
+			utils.ClearTerminal()
+			Adm_SuspensionHistory(a.AuthID, a.Email)
+			continue
+		}
+
+		if choice == 5 {
+			if err := services.UnlockAccount(ctx, auth.CurrentUser.AuthID, a.AuthID); err != nil {
+				fmt.Println("Failed to unlock account:", err)
+			} else {
+				fmt.Println("Account unlocked.")
+			}
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			continue
+		}
+
+		if self {
 			fmt.Println("Cannot modify this account.")
 			time.Sleep(1000 * time.Millisecond)
 			utils.ClearTerminal()
@@ -705,15 +819,17 @@ func Adm_AccountDetail(authID int) {
 		}
 
 		if a.DeletedAt == nil {
-			if utils.ReadConfirmation("Ban this user? (y/n): ") {
-				err := services.BanUser(ctx, a.AuthID)
+			Adm_SuspendAccount(a.AuthID, a.Role)
+		} else {
+			if utils.ReadConfirmation("Lift this suspension? (y/n): ") {
+				err := services.UnbanUser(ctx, auth.CurrentUser.AuthID, a.AuthID)
 				if err != nil {
-					fmt.Println("Failed to ban user:", err)
+					fmt.Println("Failed to lift suspension:", err)
 					time.Sleep(1000 * time.Millisecond)
 					utils.ClearTerminal()
 					continue
 				}
-				fmt.Println("User banned successfully.")
+				fmt.Println("Suspension lifted.")
 				time.Sleep(1000 * time.Millisecond)
 				utils.ClearTerminal()
 			} else {
@@ -722,24 +838,444 @@ func Adm_AccountDetail(authID int) {
 				utils.ClearTerminal()
 				continue
 			}
+		}
+	}
+}
+
+// parseSuspensionLength accepts Go durations plus whole days, e.g. "12h" or "7d"
+func parseSuspensionLength(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid length %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid length %q", s)
+	}
+	return d, nil
+}
+
+func Adm_SuspendAccount(authID int, role string) {
+	ctx := context.Background()
+
+	reason := utils.ReadLine("Reason (shown to the account at login): ")
+
+	var expiresAt *time.Time
+	length := utils.ReadLine("Length, e.g. 12h or 7d (empty = until lifted): ")
+	if length != "" {
+		d, err := parseSuspensionLength(length)
+		if err != nil {
+			fmt.Println("Error:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+		until := time.Now().Add(d)
+		expiresAt = &until
+	}
+
+	hideGames := false
+	if role == "developer" {
+		hideGames = utils.ReadConfirmation("Hide this developer's games while suspended? (y/n): ")
+	}
+
+	if !utils.ReadConfirmation("Suspend this account? (y/n): ") {
+		fmt.Println("Cancelled")
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	if err := services.BanUser(ctx, auth.CurrentUser.AuthID, authID, reason, expiresAt, hideGames); err != nil {
+		fmt.Println("Failed to suspend account:", err)
+	} else {
+		fmt.Println("Account suspended.")
+	}
+	time.Sleep(1000 * time.Millisecond)
+	utils.ClearTerminal()
+}
+
+func printSuspension(s *repository.Suspension) {
+	by := "(unknown)"
+	if s.SuspendedBy != nil {
+		by = *s.SuspendedBy
+	}
+	until := "until lifted"
+	if s.ExpiresAt != nil {
+		until = "until " + s.ExpiresAt.Format("2006-01-02 15:04")
+	}
+
+	fmt.Printf("Suspended : %s by %s, %s\n", s.CreatedAt.Format("2006-01-02 15:04"), by, until)
+	fmt.Printf("Reason    : %s\n", s.Reason)
+	if s.HideGames {
+		fmt.Printf("Games     : %d hidden\n", s.GamesHidden)
+	}
+}
+
+func Adm_SuspensionHistory(authID int, email string) {
+	ctx := context.Background()
+
+	list, err := services.SuspensionHistory(ctx, authID)
+	if err != nil {
+		fmt.Println("Error loading suspensions:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	fmt.Printf("\n=== SUSPENSION HISTORY: %s ===\n", email)
+	if len(list) == 0 {
+		fmt.Println("No suspensions.")
+	}
+	for i := range list {
+		s := &list[i]
+		printSuspension(s)
+		switch {
+		case s.LiftedAt == nil:
+			fmt.Println("Lifted    : (active)")
+		case s.LiftedBy != nil:
+			fmt.Printf("Lifted    : %s by %s\n", s.LiftedAt.Format("2006-01-02 15:04"), *s.LiftedBy)
+		default:
+			fmt.Printf("Lifted    : %s (expired)\n", s.LiftedAt.Format("2006-01-02 15:04"))
+		}
+		fmt.Println("---------------------------")
+	}
+
+	fmt.Println("[0] Back")
+	utils.ReadChoice("=> ", 0, 0)
+	utils.ClearTerminal()
+}
+
+func Adm_LoginHistory(authID int, email string) {
+	ctx := context.Background()
+
+	attempts, err := services.GetLoginHistory(ctx, authID)
+	if err != nil {
+		fmt.Println("Error loading login history:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	fmt.Printf("\n=== LOGIN HISTORY: %s ===\n", email)
+	if len(attempts) == 0 {
+		fmt.Println("No login attempts recorded.")
+	}
+	for _, la := range attempts {
+		result := "OK"
+		if !la.Success {
+			result = "FAILED"
+			if la.Reason != nil {
+				result += " (" + *la.Reason + ")"
+			}
+		}
+		fmt.Printf("%s | %-25s | %s\n", la.CreatedAt.Format("2006-01-02 15:04:05"), la.Source, result)
+	}
+
+	fmt.Println("[0] Back")
+	utils.ReadChoice("=> ", 0, 0)
+	utils.ClearTerminal()
+}
+
+func Adm_ReviewModeration() {
+	ctx := context.Background()
+
+	for {
+		list, err := services.ReviewsForModeration(ctx)
+		if err != nil {
+			fmt.Println("Failed to load reviews:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== REVIEW MODERATION ===")
+		for _, r := range list {
+			fmt.Printf("\n-- %s (Game ID: %d) --", r.GameTitle, r.GameID)
+			printReviews([]repository.Review{r})
+		}
+		if len(list) == 0 {
+			fmt.Println("No reviews yet.")
+		}
+
+		fmt.Println("\n[1] Hide Review")
+		fmt.Println("[2] Unhide Review")
+		fmt.Println("[0] Back")
+
+		choice := utils.ReadChoice("=> ", 0, 2)
+		if choice == 0 {
+			utils.ClearTerminal()
+			return
+		}
+
+		id := utils.ReadInt("Review ID: ")
+		if choice == 1 {
+			err = services.HideReview(ctx, auth.CurrentUser.AuthID, id)
 		} else {
-			if utils.ReadConfirmation("Unban this user? (y/n): ") {
-				err := services.UnbanUser(ctx, a.AuthID)
-				if err != nil {
-					fmt.Println("Failed to unban user:", err)
-					time.Sleep(1000 * time.Millisecond)
-					utils.ClearTerminal()
-					continue
-				}
-				fmt.Println("User unbanned successfully.")
+			err = services.UnhideReview(ctx, auth.CurrentUser.AuthID, id)
+		}
+
+		if err != nil {
+			fmt.Println("Failed to update review:", err)
+		} else {
+			fmt.Println("Review updated.")
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
+
+func Adm_ManageGenres() {
+	ctx := context.Background()
+
+	for {
+		tree, err := services.GenreTree(ctx)
+		if err != nil {
+			fmt.Println("Error loading genres:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== MANAGE GENRES ===")
+		if len(tree) == 0 {
+			fmt.Println("No genres found.")
+		}
+		printGenreTree(tree)
+
+		fmt.Println("\n[1] Rename Genre")
+		fmt.Println("[2] Move Genre (set parent)")
+		fmt.Println("[3] Merge Genres")
+		fmt.Println("[4] Restore Deleted Genre")
+		fmt.Println("[0] Back")
+
+		choice := utils.ReadChoice("=> ", 0, 4)
+		switch choice {
+		case 1:
+			id := utils.ReadInt("Genre ID: ")
+			name := utils.ReadLine("New Name: ")
+			err = services.RenameGenre(ctx, id, name)
+		case 2:
+			id := utils.ReadInt("Genre ID: ")
+			parent := utils.ReadInt("New Parent Genre ID (0 = top-level): ")
+			var parentID *int
+			if parent > 0 {
+				parentID = &parent
+			}
+			err = services.SetGenreParent(ctx, id, parentID)
+		case 3:
+			from := utils.ReadInt("Merge Genre ID: ")
+			into := utils.ReadInt("Into Genre ID: ")
+
+			used, cerr := services.GenreUsageCount(ctx, from)
+			if cerr != nil {
+				err = cerr
+				break
+			}
+			fmt.Printf("%d game(s) will be moved to genre %d and genre %d will be removed.\n", used, into, from)
+			if !utils.ReadConfirmation("Continue? (y/n): ") {
+				fmt.Println("Cancelled.")
 				time.Sleep(1000 * time.Millisecond)
 				utils.ClearTerminal()
-			} else {
-				fmt.Println("Cancelled")
+				continue
+			}
+			err = services.MergeGenres(ctx, auth.CurrentUser.AuthID, from, into)
+		case 4:
+			deleted, derr := services.DeletedGenres(ctx)
+			if derr != nil {
+				err = derr
+				break
+			}
+			if len(deleted) == 0 {
+				fmt.Println("No deleted genres.")
 				time.Sleep(1000 * time.Millisecond)
 				utils.ClearTerminal()
 				continue
 			}
+			fmt.Println("\n=== DELETED GENRES ===")
+			for _, g := range deleted {
+				fmt.Printf("[%d] %s\n", g.GenreID, g.GenreName)
+			}
+			id := utils.ReadInt("Genre ID to restore (0 = cancel): ")
+			if id == 0 {
+				utils.ClearTerminal()
+				continue
+			}
+			err = services.RestoreGenre(ctx, id)
+		case 0:
+			utils.ClearTerminal()
+			return
 		}
+
+		if err != nil {
+			fmt.Println("Failed:", err)
+		} else {
+			fmt.Println("Genres updated.")
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
+
+func Adm_TagModeration() {
+	ctx := context.Background()
+
+	for {
+		list, err := services.AllTags(ctx)
+		if err != nil {
+			fmt.Println("Failed to load tags:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== TAG MODERATION ===")
+		if len(list) == 0 {
+			fmt.Println("No tags yet.")
+		}
+		for _, t := range list {
+			status := ""
+			if t.BlacklistedAt != nil {
+				status = " [BLACKLISTED]"
+			}
+			fmt.Printf("[%d] %s (%d votes)%s\n", t.TagID, t.TagName, t.Votes, status)
+		}
+
+		fmt.Println("\n[1] Blacklist Tag")
+		fmt.Println("[2] Remove from Blacklist")
+		fmt.Println("[3] Blacklist New Tag Name")
+		fmt.Println("[0] Back")
+
+		choice := utils.ReadChoice("=> ", 0, 3)
+		switch choice {
+		case 1:
+			id := utils.ReadInt("Tag ID: ")
+			err = services.BlacklistTag(ctx, auth.CurrentUser.AuthID, id)
+		case 2:
+			id := utils.ReadInt("Tag ID: ")
+			err = services.UnblacklistTag(ctx, auth.CurrentUser.AuthID, id)
+		case 3:
+			name := utils.ReadLine("Tag Name: ")
+			err = services.BlacklistTagName(ctx, auth.CurrentUser.AuthID, name)
+		case 0:
+			utils.ClearTerminal()
+			return
+		}
+
+		if err != nil {
+			fmt.Println("Failed to update tag:", err)
+		} else {
+			fmt.Println("Tag updated.")
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
+
+func Adm_ExchangeRates() {
+	ctx := context.Background()
+
+	for {
+		list, err := services.ListCurrencies(ctx)
+		if err != nil {
+			fmt.Println("Failed to load currencies:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== EXCHANGE RATES ===")
+		fmt.Printf("Base currency: %s\n", services.BaseCurrencyCode())
+		if len(list) == 0 {
+			fmt.Println("No currencies configured.")
+		}
+		for _, c := range list {
+			fmt.Printf("- %s (%s): 1 %s = %.6f | updated %s\n",
+				c.Code, c.Symbol, services.BaseCurrencyCode(), c.ExchangeRate,
+				c.UpdatedAt.Format("2006-01-02 15:04"))
+		}
+
+		fmt.Println("\n[1] Add / Update Currency")
+		fmt.Println("[0] Back")
+
+		if utils.ReadChoice("=> ", 0, 1) == 0 {
+			utils.ClearTerminal()
+			return
+		}
+
+		code := utils.ReadLine("Currency Code: ")
+		symbol := utils.ReadLine("Symbol: ")
+		rate := utils.ReadFloat(fmt.Sprintf("Units per 1 %s: ", services.BaseCurrencyCode()))
+
+		if err := services.SaveCurrency(ctx, auth.CurrentUser.AuthID, code, symbol, rate); err != nil {
+			fmt.Println("Failed to save currency:", err)
+		} else {
+			fmt.Println("Currency saved.")
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
+
+func Adm_TaxRates() {
+	ctx := context.Background()
+
+	for {
+		list, err := services.ListTaxRates(ctx)
+		if err != nil {
+			fmt.Println("Failed to load tax rates:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		mode := "exclusive (added at checkout)"
+		if services.TaxInclusive() {
+			mode = "inclusive (included in listed prices)"
+		}
+
+		fmt.Println("\n=== TAX RATES ===")
+		fmt.Println("Pricing mode:", mode)
+		if len(list) == 0 {
+			fmt.Println("No tax rates configured.")
+		}
+		for _, t := range list {
+			region := "(all regions)"
+			if t.Region != nil {
+				region = *t.Region
+			}
+			fmt.Printf("[%d] %s / %s | %s %.2f%%\n", t.TaxRateID, t.Country, region, t.TaxName, t.RatePercent)
+		}
+
+		fmt.Println("\n[1] Add / Update Tax Rate")
+		fmt.Println("[2] Remove Tax Rate")
+		fmt.Println("[0] Back")
+
+		choice := utils.ReadChoice("=> ", 0, 2)
+		switch choice {
+		case 1:
+			country := utils.ReadLine("Country: ")
+			region := utils.ReadLine("Region (empty = whole country): ")
+			name := utils.ReadLine("Tax Name (e.g. VAT): ")
+			rate := utils.ReadFloat("Rate %: ")
+			err = services.SaveTaxRate(ctx, auth.CurrentUser.AuthID, country, region, name, rate)
+		case 2:
+			id := utils.ReadInt("Tax Rate ID: ")
+			err = services.RemoveTaxRate(ctx, auth.CurrentUser.AuthID, id)
+		case 0:
+			utils.ClearTerminal()
+			return
+		}
+
+		if err != nil {
+			fmt.Println("Failed to update tax rates:", err)
+		} else {
+			fmt.Println("Tax rates updated.")
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
 	}
 }
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"GamesProject/internal/repository"
+	"GamesProject/internal/services"
+	"GamesProject/internal/utils"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// auditFilter builds a filter from text input; to is inclusive, so the whole day counts
+func auditFilter(actor, action, from, to string) (repository.AuditFilter, error) {
+	var f repository.AuditFilter
+
+	if actor = strings.TrimSpace(actor); actor != "" {
+		id, err := strconv.Atoi(actor)
+		if err != nil || id <= 0 {
+			return f, errors.New("actor must be an auth ID")
+		}
+		f.ActorAuthID = id
+	}
+	f.Action = strings.TrimSpace(action)
+
+	if from = strings.TrimSpace(from); from != "" {
+		d, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return f, errors.New("start date must be YYYY-MM-DD")
+		}
+		f.From = &d
+	}
+	if to = strings.TrimSpace(to); to != "" {
+		d, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return f, errors.New("end date must be YYYY-MM-DD")
+		}
+		d = d.AddDate(0, 0, 1)
+		f.To = &d
+	}
+	return f, nil
+}
+
+func auditExport(args []string) error {
+	ctx := context.Background()
+
+	fs := flag.NewFlagSet("audit export", flag.ContinueOnError)
+	actor := fs.String("actor", "", "only actions by this auth ID")
+	action := fs.String("action", "", "action prefix, e.g. user. or game.remove")
+	from := fs.String("from", "", "first day, YYYY-MM-DD")
+	to := fs.String("to", "", "last day, YYYY-MM-DD")
+	format := fs.String("format", "csv", "csv or json")
+	out := fs.String("out", "", "output file (default: stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New(commandUsage)
+	}
+
+	f, err := auditFilter(*actor, *action, *from, *to)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := services.ExportAuditLog(ctx, f, w, *format); err != nil {
+		return err
+	}
+
+	if *out != "" {
+		fmt.Println("Audit log exported to", *out)
+	}
+	return nil
+}
+
+func Adm_AuditLog() {
+	ctx := context.Background()
+
+	fmt.Println("\n=== AUDIT LOG ===")
+	fmt.Println("Leave a field empty to match everything.")
+	f, err := auditFilter(
+		utils.ReadLine("Actor auth ID: "),
+		utils.ReadLine("Action (prefix, e.g. user.): "),
+		utils.ReadLine("From (YYYY-MM-DD): "),
+		utils.ReadLine("To (YYYY-MM-DD): "),
+	)
+	if err != nil {
+		fmt.Println("Error:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	for {
+		shown := f
+		shown.Limit = 50
+		entries, err := services.SearchAuditLog(ctx, shown)
+		if err != nil {
+			fmt.Println("Error loading audit log:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		utils.ClearTerminal()
+		fmt.Println("\n=== AUDIT LOG ===")
+		if len(entries) == 0 {
+			fmt.Println("No entries match.")
+		}
+		for _, e := range entries {
+			actor := "system"
+			if e.ActorEmail != nil {
+				actor = *e.ActorEmail
+			}
+			target := ""
+			if e.TargetType != nil && e.TargetID != nil {
+				target = fmt.Sprintf("%s #%d", *e.TargetType, *e.TargetID)
+			}
+			fmt.Printf("%s | %-25s | %-18s | %s\n", e.CreatedAt.Format("2006-01-02 15:04:05"), actor, e.Action, target)
+			if e.Before != nil {
+				fmt.Println("    before:", *e.Before)
+			}
+			if e.After != nil {
+				fmt.Println("    after: ", *e.After)
+			}
+		}
+		if len(entries) == shown.Limit {
+			fmt.Printf("(showing the latest %d, export for the full list)\n", shown.Limit)
+		}
+
+		fmt.Println("\n[1] Export")
+		fmt.Println("[0] Back")
+
+		if utils.ReadChoice("=> ", 0, 1) == 0 {
+			utils.ClearTerminal()
+			return
+		}
+
+		format := "csv"
+		if utils.ReadChoice("Format [1] CSV [2] JSON: ", 1, 2) == 2 {
+			format = "json"
+		}
+		path := utils.ReadLine("File (empty = audit-<time>." + format + "): ")
+		if path == "" {
+			path = fmt.Sprintf("audit-%s.%s", time.Now().Format("20060102-150405"), format)
+		}
+
+		if err := exportAuditFile(ctx, f, path, format); err != nil {
+			fmt.Println("Failed to export audit log:", err)
+		} else {
+			fmt.Println("Audit log exported to", path)
+		}
+		time.Sleep(1500 * time.Millisecond)
+	}
+}
+
+func exportAuditFile(ctx context.Context, f repository.AuditFilter, path, format string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := services.ExportAuditLog(ctx, f, file, format); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
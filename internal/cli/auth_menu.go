@@ -4,7 +4,9 @@ import (
 	"GamesProject/internal/auth"
 	"GamesProject/internal/utils"
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -16,6 +18,7 @@ func ProgramStart() {
 		fmt.Println("\n=== MEONG GAME SHOP ===")
 		fmt.Println("[1] Login")
 		fmt.Println("[2] Register")
+		fmt.Println("[3] Forgot Password")
 		fmt.Println("[0] Exit")
 
 		choice := utils.ReadChoice("=> ", 0, 387)
@@ -42,6 +45,9 @@ func ProgramStart() {
 		case 2:
 			RegisterUserInput(ctx)
 
+		case 3:
+			ForgotPasswordInput(ctx)
+
 		case 387:
 			RegisterAdminInput(ctx)
 
@@ -57,25 +63,119 @@ func ProgramStart() {
 	}
 }
 
+// ReadNewPassword prompts until the password meets the policy, so typos in
+// the rules don't cost the rest of the form. Errors other than a policy
+// violation (reading input, an unreadable breached list) are returned.
+func ReadNewPassword(prompt, email, name string) (string, error) {
+	fmt.Println(auth.CurrentPasswordPolicy().Hint())
+	for {
+		password, err := utils.ReadPasswordMasked(prompt)
+		if err != nil {
+			return "", err
+		}
+		err = auth.ValidatePassword(password, email, name)
+		var policyErr *auth.PolicyError
+		if errors.As(err, &policyErr) {
+			fmt.Println("Error:", err)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return password, nil
+	}
+}
+
 func RegisterUserInput(ctx context.Context) {
 
 	email := utils.ReadEmail("Email: ")
 
-	password, err := utils.ReadPasswordMasked("Password: ")
+	username := utils.ReadLimitedWord("Username (max 20 character): ", 20)
+
+	password, err := ReadNewPassword("Password: ", email, username)
 	if err != nil {
 		fmt.Println("Error reading password:", err)
 		return
 	}
 
-	username := utils.ReadLimitedWord("Username (max 20 character): ", 20)
-
 	err = auth.Register(ctx, email, password, username)
 	if err != nil {
 		fmt.Println("Error:", err)
 		return
 	}
 
-	fmt.Println("Registration successful!")
+	fmt.Println("Registration successful! We sent a verification code to", email)
+	VerifyEmailInput(ctx, email)
+}
+
+// VerifyEmailInput asks for the emailed code until it is accepted or skipped
+func VerifyEmailInput(ctx context.Context, email string) {
+	for {
+		code := utils.ReadLine("Verification code ('r' = resend, empty = later): ")
+		switch strings.ToLower(code) {
+		case "":
+			utils.ClearTerminal()
+			return
+		case "r":
+			if err := auth.SendVerification(ctx, email); err != nil {
+				fmt.Println("Failed to send code:", err)
+			} else {
+				fmt.Println("A new code was sent.")
+			}
+			continue
+		}
+
+		if err := auth.VerifyEmail(ctx, email, code); err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+
+		fmt.Println("Email verified!")
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+}
+
+func ForgotPasswordInput(ctx context.Context) {
+	email := utils.ReadEmail("Email: ")
+
+	if err := auth.RequestPasswordReset(ctx, email); err != nil {
+		fmt.Println("Failed to send reset code:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+	fmt.Println("If an account uses that email, a reset code is on its way.")
+
+	code := utils.ReadLine("Reset code (empty = cancel): ")
+	if code == "" {
+		utils.ClearTerminal()
+		return
+	}
+
+	password, err := ReadNewPassword("New password: ", email, "")
+	if err != nil {
+		fmt.Println("Error reading password:", err)
+		return
+	}
+	again, err := utils.ReadPasswordMasked("Repeat new password: ")
+	if err != nil {
+		fmt.Println("Error reading password:", err)
+		return
+	}
+	if password != again {
+		fmt.Println("Passwords do not match.")
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	if err := auth.ResetPassword(ctx, email, code, password); err != nil {
+		fmt.Println("Failed to reset password:", err)
+	} else {
+		fmt.Println("Password reset. You can log in now.")
+	}
 	time.Sleep(1000 * time.Millisecond)
 	utils.ClearTerminal()
 }
@@ -84,7 +184,7 @@ func RegisterAdminInput(ctx context.Context) {
 
 	email := utils.ReadEmail("Email: ")
 
-	password, err := utils.ReadPasswordMasked("Password: ")
+	password, err := ReadNewPassword("Password: ", email, "")
 	if err != nil {
 		fmt.Println("Error reading password:", err)
 		return
@@ -101,6 +201,26 @@ func RegisterAdminInput(ctx context.Context) {
 	utils.ClearTerminal()
 }
 
+// SecondFactorInput asks for the TOTP or recovery code until it is accepted or the login is dropped
+func SecondFactorInput(ctx context.Context) error {
+	for {
+		code := utils.ReadLine("Authentication code or recovery code (empty = cancel): ")
+		if code == "" {
+			auth.Logout()
+			return errors.New("login cancelled")
+		}
+
+		err := auth.VerifySecondFactor(ctx, code)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, auth.ErrInvalidCode) {
+			return err
+		}
+		fmt.Println("Invalid code. Try again.")
+	}
+}
+
 func LoginUserInput(ctx context.Context) error {
 
 	email := utils.ReadEmail("Email: ")
@@ -111,9 +231,30 @@ func LoginUserInput(ctx context.Context) error {
 	}
 
 	err = auth.Login(ctx, email, password)
+	if errors.Is(err, auth.ErrEmailNotVerified) {
+		fmt.Println("Please verify your email before logging in.")
+		VerifyEmailInput(ctx, email)
+		err = auth.Login(ctx, email, password)
+	}
+	if errors.Is(err, auth.ErrSecondFactor) {
+		err = SecondFactorInput(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	needsTOTP, err := auth.NeedsTOTPEnrollment(ctx)
 	if err != nil {
+		auth.Logout()
 		return err
 	}
+	if needsTOTP {
+		fmt.Println("Your account type requires two-factor authentication. Set it up to continue.")
+		if !TwoFactorSetup(ctx) {
+			auth.Logout()
+			return errors.New("two-factor authentication was not set up")
+		}
+	}
 
 	fmt.Println("Login successful! Welcome,", auth.CurrentUser.Username)
 	time.Sleep(1000 * time.Millisecond)
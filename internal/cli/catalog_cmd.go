@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"GamesProject/internal/services"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const commandUsage = `Usage:
+  myapp                                          start the interactive shop
+  myapp catalog import --developer N [--dry-run] FILE.csv|FILE.json
+  myapp catalog export --developer N [--format csv|json] [--out FILE]
+  myapp audit export [--actor AUTHID] [--action PREFIX] [--from YYYY-MM-DD] [--to YYYY-MM-DD]
+                     [--format csv|json] [--out FILE]`
+
+// RunCommand handles the non-interactive subcommands given on the command line
+func RunCommand(args []string) error {
+	if len(args) < 2 {
+		return errors.New(commandUsage)
+	}
+
+	switch args[0] + " " + args[1] {
+	case "catalog import":
+		return catalogImport(args[2:])
+	case "catalog export":
+		return catalogExport(args[2:])
+	case "audit export":
+		return auditExport(args[2:])
+	}
+	return errors.New(commandUsage)
+}
+
+func catalogImport(args []string) error {
+	ctx := context.Background()
+
+	fs := flag.NewFlagSet("catalog import", flag.ContinueOnError)
+	devID := fs.Int("developer", 0, "developer ID that owns the games")
+	dryRun := fs.Bool("dry-run", false, "validate and report without writing")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *devID <= 0 || fs.NArg() != 1 {
+		return errors.New(commandUsage)
+	}
+
+	rows, rowErrs, err := services.ParseCatalogFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	result := &services.CatalogImportResult{Errors: rowErrs}
+	if len(rowErrs) == 0 {
+		result, err = services.ImportCatalog(ctx, *devID, rows, *dryRun)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, e := range result.Errors {
+		fmt.Printf("row %d (sku %q): %s\n", e.Row, e.SKU, e.Err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("import failed with %d error(s); nothing was imported", len(result.Errors))
+	}
+
+	if *dryRun {
+		fmt.Printf("Dry run OK: %d row(s) would be created, %d updated.\n", result.Created, result.Updated)
+		return nil
+	}
+
+	fmt.Printf("Import complete: %d created, %d updated.\n", result.Created, result.Updated)
+	return nil
+}
+
+func catalogExport(args []string) error {
+	ctx := context.Background()
+
+	fs := flag.NewFlagSet("catalog export", flag.ContinueOnError)
+	devID := fs.Int("developer", 0, "developer ID to export")
+	format := fs.String("format", "", "csv or json (default: from --out extension, else csv)")
+	out := fs.String("out", "", "output file (default: stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *devID <= 0 || fs.NArg() != 0 {
+		return errors.New(commandUsage)
+	}
+
+	if *format == "" {
+		*format = "csv"
+		if *out != "" {
+			f, err := services.CatalogFormat(*out)
+			if err != nil {
+				return err
+			}
+			*format = f
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := services.ExportCatalog(ctx, *devID, w, *format); err != nil {
+		return err
+	}
+
+	if *out != "" {
+		fmt.Println("Catalog exported to", *out)
+	}
+	return nil
+}
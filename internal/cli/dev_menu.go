@@ -28,9 +28,12 @@ func Dev_Menu() {
 		fmt.Println("[1] View My Games")
 		fmt.Println("[2] Add Game")
 		fmt.Println("[3] View Sales Report")
+		fmt.Println("[4] Discounts")
+		fmt.Println("[5] Bundles")
+		fmt.Println("[6] Two-Factor Authentication")
 		fmt.Println("[0] Logout")
 
-		choice := utils.ReadChoice("=> ", 0, 3)
+		choice := utils.ReadChoice("=> ", 0, 6)
 
 		switch choice {
 		case 1:
@@ -42,6 +45,15 @@ func Dev_Menu() {
 		case 3:
 			utils.ClearTerminal()
 			Dev_Sales(devID)
+		case 4:
+			utils.ClearTerminal()
+			Dev_Discounts(devID)
+		case 5:
+			utils.ClearTerminal()
+			Dev_Bundles(devID)
+		case 6:
+			utils.ClearTerminal()
+			TwoFactorScreen()
 		case 0:
 			if !utils.ReadConfirmation("Are you sure you want to logout? (y/n): ") {
 				utils.ClearTerminal()
@@ -154,9 +166,16 @@ func Dev_ManageGameMenu(devID, gameID int) {
 		fmt.Println("[2] Remove")
 		fmt.Println("[3] Add Genre")
 		fmt.Println("[4] Edit Genres")
+		fmt.Println("[5] Schedule Discount")
+		fmt.Println("[6] Price History")
+		fmt.Println("[7] Set Base Game (DLC)")
+		fmt.Println("[8] Reviews")
+		fmt.Println("[9] Tags")
+		fmt.Println("[10] Regional Prices")
+		fmt.Println("[11] Purchase Limit")
 		fmt.Println("[0] Back")
 
-		choice := utils.ReadChoice("=> ", 0, 4)
+		choice := utils.ReadChoice("=> ", 0, 11)
 		switch choice {
 		case 1:
 			if err := Dev_EditGameByID(devID, gameID); err != nil {
@@ -171,7 +190,7 @@ func Dev_ManageGameMenu(devID, gameID int) {
 				utils.ClearTerminal()
 				continue
 			}
-			if err := services.RemoveGame(ctx, gameID, auth.CurrentUser.Role, devID); err != nil {
+			if err := services.RemoveGame(ctx, auth.CurrentUser.AuthID, gameID, auth.CurrentUser.Role, devID); err != nil {
 				fmt.Println("Failed to remove game:", err)
 				time.Sleep(1000 * time.Millisecond)
 				utils.ClearTerminal()
@@ -187,6 +206,31 @@ func Dev_ManageGameMenu(devID, gameID int) {
 		case 4:
 			utils.ClearTerminal()
 			Dev_EditGameGenre(gameID)
+		case 5:
+			Dev_ScheduleDiscount(devID, &gameID)
+		case 6:
+			utils.ClearTerminal()
+			PriceHistoryScreen(gameID)
+		case 7:
+			Dev_SetBaseGame(devID, gameID)
+		case 8:
+			utils.ClearTerminal()
+			Dev_GameReviews(devID, gameID)
+		case 9:
+			utils.ClearTerminal()
+			GameTagsScreen(gameID, devID)
+		case 10:
+			utils.ClearTerminal()
+			Dev_RegionalPrices(devID, gameID)
+		case 11:
+			limit := utils.ReadInt("Max copies per order: ")
+			if err := services.SetGameMaxPerOrder(ctx, devID, gameID, limit); err != nil {
+				fmt.Println("Failed to set limit:", err)
+			} else {
+				fmt.Println("Purchase limit updated.")
+			}
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
 		case 0:
 			utils.ClearTerminal()
 			return
@@ -419,7 +463,7 @@ func Dev_RemoveGame(devID int) {
 		utils.ClearTerminal()
 		return
 	}
-	if err := services.RemoveGame(ctx, id, auth.CurrentUser.Role, devID); err != nil {
+	if err := services.RemoveGame(ctx, auth.CurrentUser.AuthID, id, auth.CurrentUser.Role, devID); err != nil {
 		fmt.Println("Failed to remove game:", err)
 		time.Sleep(1000 * time.Millisecond)
 		utils.ClearTerminal()
@@ -444,13 +488,16 @@ func Dev_Sales(devID int) {
 			return
 		}
 
+		base := services.BaseCurrency(ctx)
+
 		if len(list) == 0 {
 			fmt.Println("You have no games or no sales.")
 		} else {
 			for _, r := range list {
 				fmt.Printf("\nGame: %s (ID: %d)\n", r.Title, r.GameID)
 				fmt.Printf("Units Sold: %d\n", r.UnitsSold)
-				fmt.Printf("Revenue: $%.2f\n", r.Revenue)
+				fmt.Printf("Revenue: %s\n", services.FormatMoney(base, r.Revenue))
+				fmt.Printf("Wishlisted by: %d\n", r.Wishlists)
 			}
 		}
 
@@ -468,3 +515,247 @@ func Dev_Sales(devID int) {
 		utils.ClearTerminal()
 	}
 }
+
+func Dev_Discounts(devID int) {
+	ctx := context.Background()
+
+	for {
+		list, err := services.DeveloperDiscounts(ctx, devID)
+		if err != nil {
+			fmt.Println("Failed to load discounts:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== DISCOUNTS ===")
+		if len(list) == 0 {
+			fmt.Println("No running or upcoming discounts.")
+		}
+		for _, d := range list {
+			target := "Whole catalog"
+			if d.GameTitle != nil {
+				target = *d.GameTitle
+			}
+			fmt.Printf("[%d] %s | -%d%% | %s -> %s\n",
+				d.DiscountID, target, d.PercentOff,
+				d.StartsAt.Format("2006-01-02 15:04"),
+				d.EndsAt.Format("2006-01-02 15:04"),
+			)
+		}
+
+		fmt.Println("\n[1] Schedule Catalog-wide Sale")
+		fmt.Println("[2] Cancel Discount")
+		fmt.Println("[0] Back")
+
+		choice := utils.ReadChoice("=> ", 0, 2)
+		switch choice {
+		case 1:
+			Dev_ScheduleDiscount(devID, nil)
+		case 2:
+			id := utils.ReadInt("Discount ID to cancel: ")
+			if !utils.ReadConfirmation("Are you sure? (y/n): ") {
+				fmt.Println("Cancelled.")
+				time.Sleep(1000 * time.Millisecond)
+				utils.ClearTerminal()
+				continue
+			}
+			if err := services.CancelDiscount(ctx, devID, id); err != nil {
+				fmt.Println("Failed to cancel discount:", err)
+			} else {
+				fmt.Println("Discount cancelled.")
+			}
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+		case 0:
+			utils.ClearTerminal()
+			return
+		}
+	}
+}
+
+// Dev_ScheduleDiscount schedules a discount for one game, or the whole catalog if gameID is nil
+func Dev_ScheduleDiscount(devID int, gameID *int) {
+	ctx := context.Background()
+
+	percent := utils.ReadInt("Percent off (1-100): ")
+	start := utils.ReadDateTime("Starts at (YYYY-MM-DD HH:MM): ")
+	end := utils.ReadDateTime("Ends at (YYYY-MM-DD HH:MM): ")
+
+	if _, err := services.ScheduleDiscount(ctx, devID, gameID, percent, start, end); err != nil {
+		fmt.Println("Failed to schedule discount:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	fmt.Println("Discount scheduled.")
+	time.Sleep(1000 * time.Millisecond)
+	utils.ClearTerminal()
+}
+
+func Dev_Bundles(devID int) {
+	ctx := context.Background()
+
+	for {
+		bundles, err := services.DeveloperBundles(ctx, devID)
+		if err != nil {
+			fmt.Println("Failed to load bundles:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== MY BUNDLES ===")
+		if len(bundles) == 0 {
+			fmt.Println("No bundles yet.")
+		}
+		for _, b := range bundles {
+			fmt.Printf("[%d] %s | -%d%% | %.2f\n", b.BundleID, b.Title, b.DiscountPercent, b.Price)
+			for _, item := range b.Items {
+				fmt.Printf("      - %s (Game ID: %d)\n", item.Title, item.GameID)
+			}
+		}
+
+		fmt.Println("\n[1] Create Bundle")
+		fmt.Println("[2] Remove Bundle")
+		fmt.Println("[0] Back")
+
+		choice := utils.ReadChoice("=> ", 0, 2)
+		switch choice {
+		case 1:
+			title := utils.ReadLine("Bundle Title: ")
+			discount := utils.ReadInt("Bundle discount percent (0-99): ")
+			gameIDs := utils.ParseIntList(utils.ReadLine("Game IDs (comma separated): "))
+
+			if _, err := services.CreateBundle(ctx, devID, title, discount, gameIDs); err != nil {
+				fmt.Println("Failed to create bundle:", err)
+			} else {
+				fmt.Println("Bundle created.")
+			}
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+		case 2:
+			id := utils.ReadInt("Bundle ID to remove: ")
+			if !utils.ReadConfirmation("Are you sure? (y/n): ") {
+				fmt.Println("Cancelled.")
+				time.Sleep(1000 * time.Millisecond)
+				utils.ClearTerminal()
+				continue
+			}
+			if err := services.RemoveBundle(ctx, devID, id); err != nil {
+				fmt.Println("Failed to remove bundle:", err)
+			} else {
+				fmt.Println("Bundle removed.")
+			}
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+		case 0:
+			utils.ClearTerminal()
+			return
+		}
+	}
+}
+
+func Dev_SetBaseGame(devID, gameID int) {
+	ctx := context.Background()
+
+	baseID := utils.ReadInt("Base Game ID (0 = standalone game): ")
+
+	var base *int
+	if baseID > 0 {
+		base = &baseID
+	}
+
+	if err := services.SetBaseGame(ctx, devID, gameID, base); err != nil {
+		fmt.Println("Failed to set base game:", err)
+	} else {
+		fmt.Println("Base game updated.")
+	}
+	time.Sleep(1000 * time.Millisecond)
+	utils.ClearTerminal()
+}
+
+func Dev_GameReviews(devID, gameID int) {
+	ctx := context.Background()
+
+	for {
+		list, err := services.GameReviews(ctx, gameID)
+		if err != nil {
+			fmt.Println("Failed to load reviews:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== REVIEWS ===")
+		printReviews(list)
+
+		fmt.Println("\n[1] Reply to Review")
+		fmt.Println("[0] Back")
+
+		if utils.ReadChoice("=> ", 0, 1) == 0 {
+			utils.ClearTerminal()
+			return
+		}
+
+		id := utils.ReadInt("Review ID: ")
+		reply := utils.ReadLine("Reply: ")
+		if err := services.ReplyToReview(ctx, devID, id, reply); err != nil {
+			fmt.Println("Failed to reply:", err)
+		} else {
+			fmt.Println("Reply posted.")
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
+
+func Dev_RegionalPrices(devID, gameID int) {
+	ctx := context.Background()
+
+	for {
+		list, err := services.RegionalPrices(ctx, gameID)
+		if err != nil {
+			fmt.Println("Failed to load prices:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== REGIONAL PRICES ===")
+		fmt.Printf("Currencies without a regional price use the %s price at the current exchange rate.\n", services.BaseCurrencyCode())
+		if len(list) == 0 {
+			fmt.Println("No regional prices set.")
+		}
+		for _, p := range list {
+			fmt.Printf("- %s: %s%.2f\n", p.CurrencyCode, p.Symbol, p.Price)
+		}
+
+		fmt.Println("\n[1] Set Regional Price")
+		fmt.Println("[2] Remove Regional Price")
+		fmt.Println("[0] Back")
+
+		choice := utils.ReadChoice("=> ", 0, 2)
+		switch choice {
+		case 1:
+			code := utils.ReadLine("Currency Code: ")
+			price := utils.ReadFloat("Price: ")
+			err = services.SetRegionalPrice(ctx, devID, gameID, code, price)
+		case 2:
+			code := utils.ReadLine("Currency Code: ")
+			err = services.RemoveRegionalPrice(ctx, devID, gameID, code)
+		case 0:
+			utils.ClearTerminal()
+			return
+		}
+
+		if err != nil {
+			fmt.Println("Failed to update price:", err)
+		} else {
+			fmt.Println("Price updated.")
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
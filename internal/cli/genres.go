@@ -0,0 +1,14 @@
+package cli
+
+import (
+	"GamesProject/internal/services"
+	"fmt"
+	"strings"
+)
+
+// printGenreTree prints genres indented under their parent genre
+func printGenreTree(tree []services.GenreNode) {
+	for _, g := range tree {
+		fmt.Printf("%s[%d] %s\n", strings.Repeat("    ", g.Depth), g.GenreID, g.GenreName)
+	}
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"GamesProject/internal/services"
+	"GamesProject/internal/utils"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PriceHistoryScreen prints the recorded prices of a game as a simple bar chart
+func PriceHistoryScreen(gameID int) {
+	ctx := context.Background()
+
+	points, err := services.GamePriceHistory(ctx, gameID)
+	if err != nil {
+		fmt.Println("Failed to load price history:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	fmt.Println("\n=== PRICE HISTORY ===")
+
+	if len(points) == 0 {
+		fmt.Println("No price history recorded.")
+	}
+
+	highest := 0.0
+	for _, p := range points {
+		if p.BasePrice > highest {
+			highest = p.BasePrice
+		}
+	}
+
+	const width = 30
+	for _, p := range points {
+		bar := 0
+		if highest > 0 {
+			bar = int(p.Price / highest * width)
+		}
+		fmt.Printf("%s | %-30s | %8.2f | %s\n",
+			p.RecordedAt.Format("2006-01-02 15:04"),
+			strings.Repeat("#", bar),
+			p.Price,
+			p.Reason,
+		)
+	}
+
+	fmt.Println("[0] Back")
+	utils.ReadChoice("=> ", 0, 0)
+	utils.ClearTerminal()
+}
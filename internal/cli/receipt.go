@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"GamesProject/internal/repository"
+	"GamesProject/internal/services"
+	"fmt"
+)
+
+// printCartIssues warns about cart lines whose price or availability changed
+func printCartIssues(cur repository.Currency, issues []services.CartIssue) {
+	if len(issues) == 0 {
+		return
+	}
+
+	fmt.Println("\n! Changes since these items were added:")
+	for _, i := range issues {
+		if i.Removed {
+			fmt.Printf("  - %s is no longer available and will be removed\n", i.Title)
+			continue
+		}
+		fmt.Printf("  - %s now costs %s (was %s)\n", i.Title,
+			services.FormatMoney(cur, i.NewPrice), services.FormatMoney(cur, i.OldPrice))
+	}
+	fmt.Println()
+}
+
+// printTaxBreakdown prints the subtotal, tax and total lines of a cart or receipt
+func printTaxBreakdown(symbol string, subtotal float64, taxName string, rate, tax float64, inclusive bool, total float64) {
+	if tax == 0 {
+		fmt.Printf("Total: %s%.2f\n", symbol, total)
+		return
+	}
+
+	if inclusive {
+		fmt.Printf("Total: %s%.2f (includes %s %.2f%%: %s%.2f)\n", symbol, total, taxName, rate, symbol, tax)
+		return
+	}
+
+	fmt.Printf("Subtotal: %s%.2f\n", symbol, subtotal)
+	fmt.Printf("%s (%.2f%%): %s%.2f\n", taxName, rate, symbol, tax)
+	fmt.Printf("Total: %s%.2f\n", symbol, total)
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"GamesProject/internal/repository"
+	"fmt"
+	"strings"
+)
+
+// printReviews is shared by the user, developer and admin review screens
+func printReviews(list []repository.Review) {
+	if len(list) == 0 {
+		fmt.Println("No reviews yet.")
+		return
+	}
+
+	for _, r := range list {
+		stars := strings.Repeat("*", r.Rating) + strings.Repeat("-", 5-r.Rating)
+		hidden := ""
+		if r.HiddenAt != nil {
+			hidden = " (HIDDEN)"
+		}
+		edited := ""
+		if r.UpdatedAt != nil {
+			edited = " (edited)"
+		}
+
+		fmt.Printf("\n[%d] %s | %s | %s%s%s\n",
+			r.ReviewID, stars, r.Username, r.CreatedAt.Format("2006-01-02"), edited, hidden)
+		if r.Body != "" {
+			fmt.Println("    " + r.Body)
+		}
+		fmt.Printf("    %d found this helpful\n", r.HelpfulCount)
+		if r.DeveloperReply != nil {
+			fmt.Println("    Developer reply: " + *r.DeveloperReply)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"GamesProject/internal/auth"
+	"GamesProject/internal/repository"
+	"GamesProject/internal/services"
+	"GamesProject/internal/utils"
+	"context"
+	"fmt"
+	"time"
+)
+
+func printTags(list []repository.TagCount) {
+	for _, t := range list {
+		fmt.Printf("[%d] %s (%d votes)\n", t.TagID, t.TagName, t.Votes)
+	}
+}
+
+// GameTagsScreen lets users and developers vote tags onto a game
+func GameTagsScreen(gameID, devID int) {
+	ctx := context.Background()
+
+	for {
+		list, err := services.GameTags(ctx, gameID)
+		if err != nil {
+			fmt.Println("Error loading tags:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== TAGS ===")
+		if len(list) == 0 {
+			fmt.Println("No tags yet.")
+		}
+		printTags(list)
+
+		fmt.Println("\n[1] Add / Vote for Tag")
+		fmt.Println("[2] Remove My Vote")
+		fmt.Println("[0] Back")
+
+		choice := utils.ReadChoice("=> ", 0, 2)
+		switch choice {
+		case 1:
+			name := utils.ReadLine("Tag: ")
+			err = services.TagGame(ctx, auth.CurrentUser.AuthID, auth.CurrentUser.Role, devID, gameID, name)
+		case 2:
+			id := utils.ReadInt("Tag ID: ")
+			err = services.RemoveTagVote(ctx, auth.CurrentUser.AuthID, gameID, id)
+		case 0:
+			utils.ClearTerminal()
+			return
+		}
+
+		if err != nil {
+			fmt.Println("Failed to update tags:", err)
+		} else {
+			fmt.Println("Tags updated.")
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
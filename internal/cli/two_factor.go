@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"GamesProject/internal/auth"
+	"GamesProject/internal/utils"
+	"context"
+	"fmt"
+	"time"
+)
+
+func printRecoveryCodes(codes []string) {
+	fmt.Println("\nRecovery codes (each works once, store them somewhere safe):")
+	for _, c := range codes {
+		fmt.Println("  ", c)
+	}
+	fmt.Println("They will not be shown again.")
+	utils.ReadLine("\nPress Enter once you have saved them...")
+}
+
+/*
+TwoFactorSetup walks through enrollment. It reports whether 2FA ended up enabled.
+*/
+func TwoFactorSetup(ctx context.Context) bool {
+	secret, uri, err := auth.BeginTOTPEnrollment(ctx, auth.CurrentUser.AuthID, auth.CurrentUser.Email)
+	if err != nil {
+		fmt.Println("Failed to start setup:", err)
+		time.Sleep(1000 * time.Millisecond)
+		return false
+	}
+
+	fmt.Println("\n=== SET UP TWO-FACTOR AUTHENTICATION ===")
+	fmt.Println("Add this account to your authenticator app.")
+	fmt.Println("Turn this URI into a QR code to scan it:")
+	fmt.Println("  ", uri)
+	fmt.Println("Or enter the key manually:")
+	fmt.Println("  ", secret)
+
+	for {
+		code := utils.ReadLine("\nCode from the app (empty = cancel): ")
+		if code == "" {
+			return false
+		}
+
+		codes, err := auth.ConfirmTOTPEnrollment(ctx, auth.CurrentUser.AuthID, code)
+		if err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+
+		fmt.Println("Two-factor authentication is on.")
+		printRecoveryCodes(codes)
+		return true
+	}
+}
+
+func TwoFactorScreen() {
+	ctx := context.Background()
+
+	for {
+		enabled, err := auth.TOTPEnabled(ctx, auth.CurrentUser.AuthID)
+		if err != nil {
+			fmt.Println("Error loading two-factor status:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== TWO-FACTOR AUTHENTICATION ===")
+		if !enabled {
+			fmt.Println("Status: OFF")
+			fmt.Println("[1] Turn On")
+			fmt.Println("[0] Back")
+
+			if utils.ReadChoice("=> ", 0, 1) == 0 {
+				utils.ClearTerminal()
+				return
+			}
+			TwoFactorSetup(ctx)
+			utils.ClearTerminal()
+			continue
+		}
+
+		left, _ := auth.RecoveryCodesLeft(ctx, auth.CurrentUser.AuthID)
+		fmt.Println("Status: ON")
+		fmt.Printf("Recovery codes left: %d\n", left)
+		fmt.Println("[1] New Recovery Codes")
+		fmt.Println("[2] Turn Off")
+		fmt.Println("[0] Back")
+
+		switch utils.ReadChoice("=> ", 0, 2) {
+		case 1:
+			code := utils.ReadLine("Current code from the app: ")
+			codes, err := auth.RegenerateRecoveryCodes(ctx, auth.CurrentUser.AuthID, code)
+			if err != nil {
+				fmt.Println("Failed to create recovery codes:", err)
+				break
+			}
+			printRecoveryCodes(codes)
+		case 2:
+			code := utils.ReadLine("Current code from the app or a recovery code: ")
+			if err := auth.DisableTOTP(ctx, auth.CurrentUser.AuthID, auth.CurrentUser.Role, code); err != nil {
+				fmt.Println("Failed to turn off two-factor authentication:", err)
+			} else {
+				fmt.Println("Two-factor authentication is off.")
+			}
+		case 0:
+			utils.ClearTerminal()
+			return
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
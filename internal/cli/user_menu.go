@@ -5,20 +5,34 @@ import (
 	"GamesProject/internal/services"
 	"GamesProject/internal/utils"
 	"context"
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 )
 
 func User_Menu() {
 	for {
+		unread, _ := services.UnreadNotificationCount(context.Background(), auth.CurrentUser.AuthID)
+
 		fmt.Printf("\n=== WELCOME, %s ===\n", auth.CurrentUser.Username)
 		fmt.Println("[1] Game Catalog")
 		fmt.Println("[2] Cart")
 		fmt.Println("[3] Order History")
+		fmt.Println("[4] Coming Soon")
+		fmt.Println("[5] My Library")
+		fmt.Printf("[6] Notifications (%d unread)\n", unread)
+		fmt.Println("[7] Bundles")
+		fmt.Println("[8] Wishlist")
+		fmt.Println("[9] You Might Like")
+		fmt.Println("[10] Browse by Genre")
+		fmt.Println("[11] Browse by Tag")
+		fmt.Println("[12] My Account")
+		fmt.Println("[13] Gifts Received")
 		fmt.Println("[0] Logout")
 
-		choice := utils.ReadChoice("=> ", 0, 3)
+		choice := utils.ReadChoice("=> ", 0, 13)
 
 		switch choice {
 		case 1:
@@ -30,6 +44,36 @@ func User_Menu() {
 		case 3:
 			utils.ClearTerminal()
 			User_OrderHistory()
+		case 4:
+			utils.ClearTerminal()
+			User_ComingSoon()
+		case 5:
+			utils.ClearTerminal()
+			User_Library()
+		case 6:
+			utils.ClearTerminal()
+			User_Notifications()
+		case 7:
+			utils.ClearTerminal()
+			User_Bundles()
+		case 8:
+			utils.ClearTerminal()
+			User_Wishlist()
+		case 9:
+			utils.ClearTerminal()
+			User_Recommendations()
+		case 10:
+			utils.ClearTerminal()
+			User_BrowseGenres()
+		case 11:
+			utils.ClearTerminal()
+			User_BrowseTags()
+		case 12:
+			utils.ClearTerminal()
+			User_Account()
+		case 13:
+			utils.ClearTerminal()
+			User_Gifts()
 		case 0:
 			if !utils.ReadConfirmation("Are you sure you want to logout? (y/n): ") {
 				utils.ClearTerminal()
@@ -65,7 +109,14 @@ func User_GameCatalog() {
 
 		fmt.Println("\n=== GAME CATALOG ===")
 		for i, g := range games {
-			fmt.Printf("[%d] %s | Game ID: %d\n", i+1, g.Title, g.GameID)
+			label := ""
+			if g.DiscountPercent > 0 {
+				label += fmt.Sprintf(" [-%d%%]", g.DiscountPercent)
+			}
+			if !g.Released {
+				label += " [Coming soon]"
+			}
+			fmt.Printf("[%d] %s%s | %.2f | Game ID: %d\n", i+1, g.Title, label, g.Price, g.GameID)
 		}
 
 		if totalPages == 0 {
@@ -118,27 +169,120 @@ func User_GameCatalog() {
 	}
 }
 
+func User_ComingSoon() {
+	ctx := context.Background()
+	page := 1
+
+	for {
+		games, totalPages, err := services.ComingSoonGames(ctx, page)
+		if err != nil {
+			fmt.Println("Error loading games:", err)
+			return
+		}
+
+		if page > totalPages && totalPages > 0 {
+			page = totalPages
+			continue
+		}
+
+		fmt.Println("\n=== COMING SOON ===")
+		for i, g := range games {
+			release := "TBA"
+			if g.ReleaseDate != nil {
+				release = g.ReleaseDate.Format("2006-01-02")
+			}
+			fmt.Printf("[%d] %s | Release: %s | Game ID: %d\n", i+1, g.Title, release, g.GameID)
+		}
+
+		if totalPages == 0 {
+			fmt.Println("No upcoming games.")
+			fmt.Println("[0] Back")
+			utils.ReadChoice("=> ", 0, 0)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Printf("--- Page %d / %d ---\n", page, totalPages)
+		fmt.Println("< Prev | Next >")
+		fmt.Println("Enter Game ID to pre-order, or 0 to go back")
+
+		input := utils.ReadPagingInput("=> ")
+
+		if input.Command == "" && input.ID == 0 {
+			utils.ClearTerminal()
+			return
+		}
+
+		if input.Command == "<" {
+			if page > 1 {
+				page--
+				utils.ClearTerminal()
+			} else {
+				fmt.Println("Already at first page.")
+				utils.ClearTerminal()
+			}
+			continue
+		}
+
+		if input.Command == ">" {
+			if page < totalPages {
+				page++
+				utils.ClearTerminal()
+			} else {
+				fmt.Println("Already at last page.")
+				utils.ClearTerminal()
+			}
+			continue
+		}
+
+		if input.ID > 0 {
+			utils.ClearTerminal()
+			User_GameMenu(input.ID)
+			continue
+		}
+
+		fmt.Println("Invalid input.")
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
+
 func User_GameMenu(gameid int) {
 	ctx := context.Background()
 	services.GameDetails(gameid)
 
+	if also, err := services.CustomersAlsoBought(ctx, gameid); err == nil && len(also) > 0 {
+		fmt.Println("\nCustomers also bought:")
+		for _, g := range also {
+			fmt.Printf("  - %s | %.2f | Game ID: %d\n", g.Title, g.Price, g.GameID)
+		}
+	}
+
+	if price, cur, err := services.LocalGamePrice(ctx, auth.CurrentUser.CustomerID, gameid); err == nil && cur.Code != services.BaseCurrencyCode() {
+		fmt.Printf("Your Price: %s (%s)\n", services.FormatMoney(cur, price), cur.Code)
+	}
+
+	wishlisted, _ := services.IsInWishlist(ctx, auth.CurrentUser.CustomerID, gameid)
+
 	fmt.Println("\n=== GAME OPTIONS ===")
 	fmt.Println("[1] Add to Cart")
+	fmt.Println("[2] Price History")
+	if wishlisted {
+		fmt.Println("[3] Remove from Wishlist")
+	} else {
+		fmt.Println("[3] Add to Wishlist")
+	}
+	fmt.Println("[4] Reviews")
+	fmt.Println("[5] Tags")
 	fmt.Println("[0] Back")
 
-	choice := utils.ReadChoice("=> ", 0, 1)
+	choice := utils.ReadChoice("=> ", 0, 5)
 	switch choice {
 	case 1:
 		// === ADD TO CART LOGIC ===
 		qty := utils.ReadInt("Quantity: ")
 
-		gamePrice, err := services.GamePrice(ctx, gameid)
-		if err != nil {
-			fmt.Println("Error:", err)
-			return
-		}
-
-		err = services.AddToCart(ctx, auth.CurrentUser.CustomerID, gameid, qty, gamePrice)
+		err := services.AddToCart(ctx, auth.CurrentUser.CustomerID, gameid, qty)
 		if err != nil {
 			fmt.Println("Failed to add to cart:", err)
 			time.Sleep(1000 * time.Millisecond)
@@ -149,6 +293,36 @@ func User_GameMenu(gameid int) {
 			utils.ClearTerminal()
 		}
 
+	case 2:
+		utils.ClearTerminal()
+		PriceHistoryScreen(gameid)
+
+	case 3:
+		var err error
+		if wishlisted {
+			err = services.RemoveFromWishlist(ctx, auth.CurrentUser.CustomerID, gameid)
+		} else {
+			err = services.AddToWishlist(ctx, auth.CurrentUser.CustomerID, gameid)
+		}
+
+		if err != nil {
+			fmt.Println("Failed to update wishlist:", err)
+		} else if wishlisted {
+			fmt.Println("Removed from wishlist.")
+		} else {
+			fmt.Println("Added to wishlist!")
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+
+	case 4:
+		utils.ClearTerminal()
+		User_GameReviews(gameid)
+
+	case 5:
+		utils.ClearTerminal()
+		GameTagsScreen(gameid, 0)
+
 	case 0:
 		utils.ClearTerminal()
 		return
@@ -176,88 +350,87 @@ func User_Cart() {
 			return
 		}
 
+		cur, err := services.CustomerCurrency(ctx, auth.CurrentUser.CustomerID)
+		if err != nil {
+			fmt.Println("Error loading currency:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
 		for i, item := range cart.Items {
-			fmt.Printf("[%d] %s x%d (%.2f each) | Item ID: %d\n",
-				i+1, item.Title, item.Quantity, item.PriceAtPurchase, item.OrderItemID)
+			bundle := ""
+			if item.BundleTitle != nil {
+				bundle = fmt.Sprintf(" [Bundle: %s]", *item.BundleTitle)
+			}
+			fmt.Printf("[%d] %s%s x%d (%s each) | Item ID: %d\n",
+				i+1, item.Title, bundle, item.Quantity, services.FormatMoney(cur, item.PriceAtPurchase), item.OrderItemID)
+		}
+
+		quote, err := services.QuoteTax(ctx, auth.CurrentUser.CustomerID, cart.Items)
+		if err != nil {
+			fmt.Println("Error calculating tax:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
 		}
 
-		fmt.Printf("Total: %.2f\n", cart.Total)
+		printTaxBreakdown(cur.Symbol, quote.Subtotal, quote.TaxName, quote.Rate, quote.Tax, quote.Inclusive, quote.Total)
+
+		issues, err := services.CheckCart(ctx, auth.CurrentUser.CustomerID)
+		if err != nil {
+			fmt.Println("Error checking cart:", err)
+		}
+		printCartIssues(cur, issues)
 
 		fmt.Println("[1] Buy All Items")
 		fmt.Println("[2] Remove Item")
 		fmt.Println("[3] Clear Cart")
+		fmt.Println("[4] Change Quantity")
+		fmt.Println("[5] Buy as Gift")
 		fmt.Println("[0] Back")
 
-		choice := utils.ReadChoice("=> ", 0, 3)
+		choice := utils.ReadChoice("=> ", 0, 5)
 		switch choice {
 
-		case 1:
+		case 1, 5:
 			// === CHECKOUT & PAYMENT FLOW ===
-			orderID, total, err := services.CheckoutCart(ctx, auth.CurrentUser.CustomerID)
-			if err != nil {
-				fmt.Println("Checkout failed:", err)
-				time.Sleep(1000 * time.Millisecond)
-				utils.ClearTerminal()
-				continue
-			}
-
-			fmt.Println("\n=== PAYMENT METHODS ===")
-
-			methods, err := services.ListPaymentMethods(ctx)
-			if err != nil {
-				fmt.Println("Failed to load payment methods:", err)
-				time.Sleep(1000 * time.Millisecond)
-				utils.ClearTerminal()
-				continue
-			}
-
-			for _, m := range methods {
-				fmt.Printf("[%d] %s\n", m.PaymentMethodID, m.Name)
-			}
-
-			methodChoice := utils.ReadInt("=> ")
-
-			var chosenMethodID int
-			for _, m := range methods {
-				if m.PaymentMethodID == methodChoice {
-					chosenMethodID = m.PaymentMethodID
-					break
+			var gift *services.GiftRequest
+			if choice == 5 {
+				gift = &services.GiftRequest{
+					Recipient: utils.ReadLine("Recipient email or username: "),
+					Message:   utils.ReadLine("Gift message (optional): "),
 				}
 			}
 
-			if chosenMethodID == 0 {
-				fmt.Println("Invalid payment method.")
-				time.Sleep(1000 * time.Millisecond)
+			orderID, total, err := services.CheckoutCart(ctx, auth.CurrentUser.CustomerID, gift)
+			if errors.Is(err, services.ErrCartChanged) {
+				fmt.Println("Prices or availability changed since you added these items (see above).")
+				if !utils.ReadConfirmation("Accept the changes and update your cart? (y/n): ") {
+					utils.ClearTerminal()
+					continue
+				}
+				if err := services.AcknowledgeCartChanges(ctx, auth.CurrentUser.CustomerID); err != nil {
+					fmt.Println("Failed to update cart:", err)
+					time.Sleep(1000 * time.Millisecond)
+				}
 				utils.ClearTerminal()
 				continue
 			}
-
-			pid, err := services.StartPaymentForOrder(ctx, orderID, chosenMethodID, total)
 			if err != nil {
-				fmt.Println("Failed to create payment:", err)
-				time.Sleep(1000 * time.Millisecond)
-				utils.ClearTerminal()
-				continue
-			}
-
-			fmt.Println("Processing payment...")
-
-			if err := services.ConfirmPayment(ctx, pid); err != nil {
-				fmt.Println("Payment failed:", err)
-				_ = services.FailPayment(ctx, pid)
+				fmt.Println("Checkout failed:", err)
 				time.Sleep(1000 * time.Millisecond)
 				utils.ClearTerminal()
 				continue
 			}
 
-			fmt.Println("Payment successful! Thank you.")
-			time.Sleep(1000 * time.Millisecond)
 			utils.ClearTerminal()
+			User_PayOrder(orderID, total)
 
 		case 2:
 			id := utils.ReadInt("Enter OrderItemID to remove: ")
 
-			err := services.RemoveFromCart(ctx, id)
+			err := services.RemoveFromCart(ctx, auth.CurrentUser.CustomerID, id)
 			if err != nil {
 				fmt.Println("Error:", err)
 				time.Sleep(1000 * time.Millisecond)
@@ -282,12 +455,94 @@ func User_Cart() {
 			}
 			continue
 
+		case 4:
+			id := utils.ReadInt("Enter OrderItemID: ")
+			qty := utils.ReadInt("New Quantity: ")
+
+			if err := services.UpdateQuantity(ctx, auth.CurrentUser.CustomerID, id, qty); err != nil {
+				fmt.Println("Error:", err)
+			} else {
+				fmt.Println("Quantity updated.")
+			}
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			continue
+
 		case 0:
 			return
 		}
 	}
 }
 
+// User_PayOrder takes payment for a checked-out order. On failure the order
+// stays pending so it can be retried or cancelled from Order History.
+func User_PayOrder(orderID int, total float64) {
+	ctx := context.Background()
+
+	fmt.Println("\n=== PAYMENT METHODS ===")
+
+	methods, err := services.ListPaymentMethods(ctx)
+	if err != nil {
+		fmt.Println("Failed to load payment methods:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	for _, m := range methods {
+		fmt.Printf("[%d] %s\n", m.PaymentMethodID, m.Name)
+	}
+	fmt.Println("[0] Pay Later")
+
+	methodChoice := utils.ReadInt("=> ")
+
+	var chosenMethodID int
+	for _, m := range methods {
+		if m.PaymentMethodID == methodChoice {
+			chosenMethodID = m.PaymentMethodID
+			break
+		}
+	}
+
+	unpaid := fmt.Sprintf("Your order is waiting for payment. Pay or cancel it from Order History within %s.", services.OrderPaymentTimeout())
+
+	if chosenMethodID == 0 {
+		if methodChoice != 0 {
+			fmt.Println("Invalid payment method.")
+		}
+		fmt.Println(unpaid)
+		time.Sleep(2000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	pid, err := services.StartPaymentForOrder(ctx, orderID, chosenMethodID, total)
+	if err != nil {
+		fmt.Println("Failed to create payment:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	fmt.Println("Processing payment...")
+
+	if err := services.ConfirmPayment(ctx, pid); err != nil {
+		fmt.Println("Payment failed:", err)
+		_ = services.FailPayment(ctx, pid)
+		fmt.Println(unpaid)
+		time.Sleep(2000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	fmt.Println("Payment successful! Thank you.")
+	if inv, err := services.CustomerInvoice(ctx, auth.CurrentUser.CustomerID, orderID); err == nil {
+		fmt.Printf("Invoice %s issued. You can download it from Order History.\n", inv.InvoiceNumber)
+	}
+	time.Sleep(2000 * time.Millisecond)
+	utils.ClearTerminal()
+}
+
 func User_OrderHistory() {
 	ctx := context.Background()
 
@@ -314,12 +569,30 @@ func User_OrderHistory() {
 	})
 
 	for i, h := range history {
-		fmt.Printf("Order #%d | $%.2f | %s\n",
+		fmt.Printf("Order #%d | %s%.2f | %s | %s\n",
 			i+1,
+			h.CurrencySymbol,
 			h.TotalPrice,
 			h.OrderDate.Format("2006-01-02 15:04"),
+			strings.ToUpper(h.Status),
 		)
 
+		if h.GiftRecipient != nil {
+			fmt.Printf("Gift to %s", *h.GiftRecipient)
+			if h.GiftStatus != nil {
+				fmt.Printf(" (%s)", *h.GiftStatus)
+			}
+			fmt.Println()
+		}
+
+		if h.TaxTotal > 0 {
+			if h.TaxInclusive {
+				fmt.Printf("Includes tax: %s%.2f\n", h.CurrencySymbol, h.TaxTotal)
+			} else {
+				fmt.Printf("Tax: %s%.2f\n", h.CurrencySymbol, h.TaxTotal)
+			}
+		}
+
 		fmt.Printf("Payment: %s", h.PaymentStatus)
 		if h.PaidAt != nil {
 			fmt.Printf(" at %s", h.PaidAt.Format("2006-01-02 15:04"))
@@ -328,7 +601,870 @@ func User_OrderHistory() {
 		fmt.Println("---------------------------")
 	}
 
-	fmt.Println("[0] Back")
-	utils.ReadChoice("=> ", 0, 0)
+	n := utils.ReadChoice("Enter Order # to view details, or 0 to go back: ", 0, len(history))
+	utils.ClearTerminal()
+	if n == 0 {
+		return
+	}
+	User_OrderDetail(history[n-1].OrderID)
+}
+
+func User_OrderDetail(orderID int) {
+	ctx := context.Background()
+
+	for {
+		d, err := services.CustomerOrderDetail(ctx, auth.CurrentUser.CustomerID, orderID)
+		if err != nil {
+			fmt.Println("Error loading order:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		s := d.Summary
+		fmt.Printf("\n=== ORDER %d ===\n", s.OrderID)
+		fmt.Println("Placed:", s.OrderDate.Format("2006-01-02 15:04"))
+		fmt.Println("Status:", strings.ToUpper(s.Status))
+		if s.Status == "pending" && s.CheckedOutAt != nil {
+			fmt.Println("Pay before:", s.CheckedOutAt.Add(services.OrderPaymentTimeout()).Format("2006-01-02 15:04"))
+		}
+
+		if gift, err := services.OrderGift(ctx, orderID); err == nil && gift != nil {
+			fmt.Printf("Gift to %s | %s\n", gift.RecipientName, strings.ToUpper(gift.Status))
+			if gift.Message != nil {
+				fmt.Printf("Message: %q\n", *gift.Message)
+			}
+		}
+
+		fmt.Println("\nItems:")
+		var subtotal float64
+		for _, l := range d.Lines {
+			line := float64(l.Quantity) * l.UnitPrice
+			subtotal += line
+			fmt.Printf("- %s x%d @ %s%.2f = %s%.2f", l.Title, l.Quantity, d.Symbol, l.UnitPrice, d.Symbol, line)
+			if l.ListPrice != nil && *l.ListPrice > l.UnitPrice {
+				fmt.Printf(" (was %s%.2f, saved %s%.2f each)", d.Symbol, *l.ListPrice, d.Symbol, *l.ListPrice-l.UnitPrice)
+			}
+			if l.BundleTitle != nil {
+				fmt.Printf(" [Bundle: %s]", *l.BundleTitle)
+			}
+			fmt.Println()
+		}
+		printTaxBreakdown(d.Symbol, subtotal, "Tax", s.TaxRate, s.TaxTotal, s.TaxInclusive, s.TotalPrice)
+
+		fmt.Println("\nPayments:")
+		if len(d.Payments) == 0 {
+			fmt.Println("No payment attempts.")
+		}
+		for _, p := range d.Payments {
+			fmt.Printf("- Payment %d via %s | %s%.2f | %s\n", p.PaymentID, p.Method, d.Symbol, p.AmountPaid, p.PaymentStatus)
+			fmt.Printf("    %s  created\n", p.CreatedAt.Format("2006-01-02 15:04:05"))
+			for _, l := range p.Logs {
+				fmt.Printf("    %s  %s -> %s\n", l.ChangedAt.Format("2006-01-02 15:04:05"), l.OldStatus, l.NewStatus)
+			}
+		}
+
+		fmt.Println("\nGames Granted:")
+		if len(d.Entitlements) == 0 {
+			fmt.Println("None yet.")
+		}
+		for _, e := range d.Entitlements {
+			status := "Unlocked"
+			if e.UnlockedAt == nil {
+				status = "Pre-ordered"
+			}
+			fmt.Printf("- %s | %s | Game ID: %d\n", e.Title, status, e.GameID)
+		}
+
+		fmt.Println("\n[1] Invoice")
+		fmt.Println("[2] View Game")
+		maxChoice := 2
+		if s.Status == "pending" {
+			fmt.Println("[3] Pay Now")
+			fmt.Println("[4] Cancel Order")
+			maxChoice = 4
+		}
+		fmt.Println("[0] Back")
+
+		switch utils.ReadChoice("=> ", 0, maxChoice) {
+		case 1:
+			User_DownloadInvoice(orderID)
+		case 2:
+			id := utils.ReadInt("Game ID: ")
+			utils.ClearTerminal()
+			User_GameMenu(id)
+		case 3:
+			User_PayOrder(orderID, s.TotalPrice)
+		case 4:
+			if !utils.ReadConfirmation("Cancel this order? (y/n): ") {
+				utils.ClearTerminal()
+				continue
+			}
+			if err := services.CancelOrder(ctx, auth.CurrentUser.CustomerID, orderID); err != nil {
+				fmt.Println("Failed to cancel order:", err)
+			} else {
+				fmt.Println("Order cancelled.")
+			}
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+		case 0:
+			utils.ClearTerminal()
+			return
+		}
+	}
+}
+
+func User_Gifts() {
+	ctx := context.Background()
+
+	for {
+		gifts, err := services.ReceivedGifts(ctx, auth.CurrentUser.CustomerID)
+		if err != nil {
+			fmt.Println("Error loading gifts:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== GIFTS RECEIVED ===")
+		if len(gifts) == 0 {
+			fmt.Println("No gifts yet.")
+		}
+		for _, g := range gifts {
+			fmt.Printf("Gift ID: %d | From: %s | %s | %s\n",
+				g.GiftID, g.SenderName, g.CreatedAt.Format("2006-01-02 15:04"), strings.ToUpper(g.Status))
+			fmt.Println("Games:", g.Titles)
+			if g.Message != nil {
+				fmt.Printf("Message: %q\n", *g.Message)
+			}
+			fmt.Println("---------------------------")
+		}
+
+		fmt.Println("[1] Accept Gift")
+		fmt.Println("[2] Decline Gift")
+		fmt.Println("[0] Back")
+
+		switch utils.ReadChoice("=> ", 0, 2) {
+		case 1:
+			id := utils.ReadInt("Gift ID: ")
+			if err := services.AcceptGift(ctx, auth.CurrentUser.CustomerID, id); err != nil {
+				fmt.Println("Failed to accept gift:", err)
+			} else {
+				fmt.Println("Gift accepted. The games are in your library.")
+			}
+		case 2:
+			id := utils.ReadInt("Gift ID: ")
+			if !utils.ReadConfirmation("Decline this gift? The sender will be refunded. (y/n): ") {
+				utils.ClearTerminal()
+				continue
+			}
+			if err := services.DeclineGift(ctx, auth.CurrentUser.CustomerID, id); err != nil {
+				fmt.Println("Failed to decline gift:", err)
+			} else {
+				fmt.Println("Gift declined.")
+			}
+		case 0:
+			utils.ClearTerminal()
+			return
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
+
+func User_DownloadInvoice(orderID int) {
+	ctx := context.Background()
+
+	inv, err := services.CustomerInvoice(ctx, auth.CurrentUser.CustomerID, orderID)
+	if err != nil {
+		fmt.Println("Failed to load invoice:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	fmt.Println()
+	fmt.Print(services.InvoiceText(inv))
+
+	fmt.Println("\n[1] Save as Text")
+	fmt.Println("[2] Save as PDF")
+	fmt.Println("[0] Back")
+
+	format := ""
+	switch utils.ReadChoice("=> ", 0, 2) {
+	case 1:
+		format = "txt"
+	case 2:
+		format = "pdf"
+	case 0:
+		utils.ClearTerminal()
+		return
+	}
+
+	path, err := services.SaveInvoice(inv, format)
+	if err != nil {
+		fmt.Println("Failed to save invoice:", err)
+	} else {
+		fmt.Println("Invoice saved to", path)
+	}
+	time.Sleep(2000 * time.Millisecond)
+	utils.ClearTerminal()
+}
+
+func User_Library() {
+	ctx := context.Background()
+
+	library, err := services.GetLibrary(ctx, auth.CurrentUser.CustomerID)
+	if err != nil {
+		fmt.Println("Error loading library:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	fmt.Println("\n=== MY LIBRARY ===")
+
+	if len(library) == 0 {
+		fmt.Println("You don't own any games yet.")
+	}
+
+	for i, e := range library {
+		if e.UnlockedAt != nil {
+			fmt.Printf("[%d] %s | Unlocked %s\n", i+1, e.Title, e.UnlockedAt.Format("2006-01-02"))
+			continue
+		}
+
+		release := "TBA"
+		if e.ReleaseDate != nil {
+			release = e.ReleaseDate.Format("2006-01-02")
+		}
+		fmt.Printf("[%d] %s | Pre-ordered, unlocks on %s\n", i+1, e.Title, release)
+	}
+
+	fmt.Println("[0] Back")
+	utils.ReadChoice("=> ", 0, 0)
+	utils.ClearTerminal()
+}
+
+func User_Notifications() {
+	ctx := context.Background()
+
+	list, err := services.GetNotifications(ctx, auth.CurrentUser.AuthID)
+	if err != nil {
+		fmt.Println("Error loading notifications:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	fmt.Println("\n=== NOTIFICATIONS ===")
+
+	if len(list) == 0 {
+		fmt.Println("No notifications.")
+	}
+
+	for _, n := range list {
+		marker := " "
+		if n.ReadAt == nil {
+			marker = "*"
+		}
+		fmt.Printf("%s %s | %s\n", marker, n.CreatedAt.Format("2006-01-02 15:04"), n.Message)
+	}
+
+	if err := services.MarkNotificationsRead(ctx, auth.CurrentUser.AuthID); err != nil {
+		fmt.Println("Failed to mark notifications as read:", err)
+	}
+
+	fmt.Println("[0] Back")
+	utils.ReadChoice("=> ", 0, 0)
+	utils.ClearTerminal()
+}
+
+func User_Bundles() {
+	ctx := context.Background()
+
+	for {
+		bundles, err := services.AllBundles(ctx)
+		if err != nil {
+			fmt.Println("Error loading bundles:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== BUNDLES ===")
+
+		if len(bundles) == 0 {
+			fmt.Println("No bundles available.")
+			fmt.Println("[0] Back")
+			utils.ReadChoice("=> ", 0, 0)
+			utils.ClearTerminal()
+			return
+		}
+
+		for _, b := range bundles {
+			fmt.Printf("[%d] %s by %s | %.2f (was %.2f, -%d%%)\n",
+				b.BundleID, b.Title, b.DeveloperName, b.Price, b.FullPrice, b.DiscountPercent)
+			for _, item := range b.Items {
+				fmt.Printf("      - %s (%.2f)\n", item.Title, item.Price)
+			}
+		}
+
+		id := utils.ReadInt("Enter Bundle ID to add to cart, or 0 to go back: ")
+		if id == 0 {
+			utils.ClearTerminal()
+			return
+		}
+
+		if err := services.AddBundleToCart(ctx, auth.CurrentUser.CustomerID, id); err != nil {
+			fmt.Println("Failed to add bundle:", err)
+		} else {
+			fmt.Println("Bundle added to cart!")
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
+
+func User_Wishlist() {
+	ctx := context.Background()
+
+	for {
+		list, err := services.GetWishlist(ctx, auth.CurrentUser.CustomerID)
+		if err != nil {
+			fmt.Println("Error loading wishlist:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== WISHLIST ===")
+
+		if len(list) == 0 {
+			fmt.Println("Your wishlist is empty!")
+			fmt.Println("[0] Back")
+			utils.ReadChoice("=> ", 0, 0)
+			utils.ClearTerminal()
+			return
+		}
+
+		for i, w := range list {
+			label := ""
+			if w.DiscountPercent > 0 {
+				label += fmt.Sprintf(" [-%d%%]", w.DiscountPercent)
+			}
+			if !w.Released {
+				label += " [Coming soon]"
+			}
+			fmt.Printf("[%d] %s%s | %.2f | Game ID: %d\n", i+1, w.Title, label, w.Price, w.GameID)
+		}
+
+		fmt.Println("[1] Move to Cart")
+		fmt.Println("[2] Remove from Wishlist")
+		fmt.Println("[0] Back")
+
+		choice := utils.ReadChoice("=> ", 0, 2)
+		switch choice {
+		case 1:
+			id := utils.ReadInt("Game ID to move to cart: ")
+			if err := services.MoveWishlistToCart(ctx, auth.CurrentUser.CustomerID, id); err != nil {
+				fmt.Println("Failed to move to cart:", err)
+			} else {
+				fmt.Println("Moved to cart!")
+			}
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+		case 2:
+			id := utils.ReadInt("Game ID to remove: ")
+			if err := services.RemoveFromWishlist(ctx, auth.CurrentUser.CustomerID, id); err != nil {
+				fmt.Println("Failed to remove:", err)
+			} else {
+				fmt.Println("Removed from wishlist.")
+			}
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+		case 0:
+			utils.ClearTerminal()
+			return
+		}
+	}
+}
+
+func User_GameReviews(gameID int) {
+	ctx := context.Background()
+
+	for {
+		list, err := services.GameReviews(ctx, gameID)
+		if err != nil {
+			fmt.Println("Error loading reviews:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== REVIEWS ===")
+		printReviews(list)
+
+		fmt.Println("\n[1] Write / Edit My Review")
+		fmt.Println("[2] Delete My Review")
+		fmt.Println("[3] Mark Review as Helpful")
+		fmt.Println("[0] Back")
+
+		choice := utils.ReadChoice("=> ", 0, 3)
+		switch choice {
+		case 1:
+			rating := utils.ReadChoice("Rating (1-5): ", 1, 5)
+			body := utils.ReadLine("Review: ")
+			if err := services.WriteReview(ctx, auth.CurrentUser.CustomerID, gameID, rating, body); err != nil {
+				fmt.Println("Failed to save review:", err)
+			} else {
+				fmt.Println("Review saved.")
+			}
+		case 2:
+			if !utils.ReadConfirmation("Delete your review? (y/n): ") {
+				fmt.Println("Cancelled.")
+				break
+			}
+			if err := services.DeleteReview(ctx, auth.CurrentUser.CustomerID, gameID); err != nil {
+				fmt.Println("Failed to delete review:", err)
+			} else {
+				fmt.Println("Review deleted.")
+			}
+		case 3:
+			id := utils.ReadInt("Review ID: ")
+			if err := services.MarkReviewHelpful(ctx, auth.CurrentUser.CustomerID, id); err != nil {
+				fmt.Println("Failed:", err)
+			} else {
+				fmt.Println("Thanks for your feedback!")
+			}
+		case 0:
+			utils.ClearTerminal()
+			return
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
+
+func User_Recommendations() {
+	ctx := context.Background()
+
+	for {
+		list, err := services.YouMightLike(ctx, auth.CurrentUser.CustomerID)
+		if err != nil {
+			fmt.Println("Error loading recommendations:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== YOU MIGHT LIKE ===")
+
+		if len(list) == 0 {
+			fmt.Println("No recommendations yet. Buy a few games and check back later!")
+			fmt.Println("[0] Back")
+			utils.ReadChoice("=> ", 0, 0)
+			utils.ClearTerminal()
+			return
+		}
+
+		for i, g := range list {
+			label := ""
+			if g.DiscountPercent > 0 {
+				label = fmt.Sprintf(" [-%d%%]", g.DiscountPercent)
+			}
+			fmt.Printf("[%d] %s%s | %.2f | Game ID: %d\n", i+1, g.Title, label, g.Price, g.GameID)
+		}
+
+		id := utils.ReadInt("Enter Game ID to view, or 0 to go back: ")
+		if id == 0 {
+			utils.ClearTerminal()
+			return
+		}
+
+		utils.ClearTerminal()
+		User_GameMenu(id)
+	}
+}
+
+func User_BrowseGenres() {
+	ctx := context.Background()
+
+	for {
+		tree, err := services.GenreTree(ctx)
+		if err != nil {
+			fmt.Println("Error loading genres:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== BROWSE BY GENRE ===")
+		if len(tree) == 0 {
+			fmt.Println("No genres found.")
+		}
+		printGenreTree(tree)
+
+		id := utils.ReadInt("Enter Genre ID (includes sub-genres), or 0 to go back: ")
+		if id == 0 {
+			utils.ClearTerminal()
+			return
+		}
+
+		utils.ClearTerminal()
+		User_GenreGames(id)
+	}
+}
+
+func User_GenreGames(genreID int) {
+	ctx := context.Background()
+	page := 1
+
+	for {
+		games, totalPages, err := services.GamesByGenre(ctx, genreID, page)
+		if err != nil {
+			fmt.Println("Error loading games:", err)
+			return
+		}
+
+		if page > totalPages && totalPages > 0 {
+			page = totalPages
+			continue
+		}
+
+		fmt.Println("\n=== GAMES IN GENRE ===")
+		for i, g := range games {
+			label := ""
+			if g.DiscountPercent > 0 {
+				label += fmt.Sprintf(" [-%d%%]", g.DiscountPercent)
+			}
+			if !g.Released {
+				label += " [Coming soon]"
+			}
+			fmt.Printf("[%d] %s%s | %.2f | Game ID: %d\n", i+1, g.Title, label, g.Price, g.GameID)
+		}
+
+		if totalPages == 0 {
+			fmt.Println("No games found.")
+			fmt.Println("[0] Back")
+			utils.ReadChoice("=> ", 0, 0)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Printf("--- Page %d / %d ---\n", page, totalPages)
+		fmt.Println("< Prev | Next >")
+		fmt.Println("Enter Game ID to view, or 0 to go back")
+
+		input := utils.ReadPagingInput("=> ")
+
+		if input.Command == "" && input.ID == 0 {
+			utils.ClearTerminal()
+			return
+		}
+
+		if input.Command == "<" {
+			if page > 1 {
+				page--
+				utils.ClearTerminal()
+			} else {
+				fmt.Println("Already at first page.")
+				utils.ClearTerminal()
+			}
+			continue
+		}
+
+		if input.Command == ">" {
+			if page < totalPages {
+				page++
+				utils.ClearTerminal()
+			} else {
+				fmt.Println("Already at last page.")
+				utils.ClearTerminal()
+			}
+			continue
+		}
+
+		if input.ID > 0 {
+			utils.ClearTerminal()
+			User_GameMenu(input.ID)
+			continue
+		}
+
+		fmt.Println("Invalid input.")
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
+
+func User_BrowseTags() {
+	ctx := context.Background()
+
+	for {
+		term := utils.ReadLine("Search tags (empty = popular tags, 0 = back): ")
+		if term == "0" {
+			utils.ClearTerminal()
+			return
+		}
+
+		list, err := services.SearchTags(ctx, term)
+		if err != nil {
+			fmt.Println("Error searching tags:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== TAGS ===")
+		if len(list) == 0 {
+			fmt.Println("No tags found.")
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			continue
+		}
+		printTags(list)
+
+		id := utils.ReadInt("Enter Tag ID to filter games, or 0 to search again: ")
+		utils.ClearTerminal()
+		if id == 0 {
+			continue
+		}
+		User_TagGames(id)
+	}
+}
+
+func User_TagGames(tagID int) {
+	ctx := context.Background()
+
+	for {
+		games, err := services.GamesByTag(ctx, tagID)
+		if err != nil {
+			fmt.Println("Error loading games:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+
+		fmt.Println("\n=== GAMES WITH TAG ===")
+		if len(games) == 0 {
+			fmt.Println("No games found.")
+			fmt.Println("[0] Back")
+			utils.ReadChoice("=> ", 0, 0)
+			utils.ClearTerminal()
+			return
+		}
+
+		for i, g := range games {
+			label := ""
+			if g.DiscountPercent > 0 {
+				label += fmt.Sprintf(" [-%d%%]", g.DiscountPercent)
+			}
+			if !g.Released {
+				label += " [Coming soon]"
+			}
+			fmt.Printf("[%d] %s%s | %.2f | Game ID: %d\n", i+1, g.Title, label, g.Price, g.GameID)
+		}
+
+		id := utils.ReadInt("Enter Game ID to view, or 0 to go back: ")
+		if id == 0 {
+			utils.ClearTerminal()
+			return
+		}
+
+		utils.ClearTerminal()
+		User_GameMenu(id)
+	}
+}
+
+func User_Account() {
+	ctx := context.Background()
+
+	for {
+		p, err := services.CustomerProfile(ctx, auth.CurrentUser.CustomerID)
+		if err != nil {
+			fmt.Println("Error loading account:", err)
+			time.Sleep(1000 * time.Millisecond)
+			utils.ClearTerminal()
+			return
+		}
+		pending, _ := auth.PendingEmailChange(ctx, auth.CurrentUser.AuthID)
+
+		orNone := func(s *string) string {
+			if s == nil {
+				return "(not set)"
+			}
+			return *s
+		}
+
+		fmt.Println("\n=== MY ACCOUNT ===")
+		fmt.Println("Username :", p.Username)
+		fmt.Println("Email    :", p.Email)
+		if pending != nil {
+			fmt.Println("           (change to", *pending, "awaiting confirmation)")
+		}
+		fmt.Println("Full Name:", orNone(p.FullName))
+		fmt.Println("Phone    :", orNone(p.Phone))
+		fmt.Println("Address  :", orNone(p.Address))
+
+		fmt.Println("\n[1] Edit Name & Phone")
+		fmt.Println("[2] Change Username")
+		fmt.Println("[3] Change Email")
+		fmt.Println("[4] Confirm Email Change")
+		fmt.Println("[5] Change Password")
+		fmt.Println("[6] Billing Address")
+		fmt.Println("[7] Currency")
+		fmt.Println("[0] Back")
+
+		switch utils.ReadChoice("=> ", 0, 7) {
+		case 1:
+			fmt.Println("Leave a field empty to clear it.")
+			name := utils.ReadLine("Full name: ")
+			phone := utils.ReadLine("Phone: ")
+			if err := services.UpdateProfile(ctx, auth.CurrentUser.CustomerID, name, phone); err != nil {
+				fmt.Println("Failed to update profile:", err)
+			} else {
+				fmt.Println("Profile updated.")
+			}
+		case 2:
+			username := utils.ReadLine("New username: ")
+			if err := services.ChangeUsername(ctx, auth.CurrentUser.CustomerID, username); err != nil {
+				fmt.Println("Failed to change username:", err)
+			} else {
+				auth.CurrentUser.Username = username
+				fmt.Println("Username changed.")
+			}
+		case 3:
+			email := utils.ReadEmail("New email: ")
+			password, err := utils.ReadPasswordMasked("Current password: ")
+			if err != nil {
+				fmt.Println("Error reading password:", err)
+				break
+			}
+			if err := auth.RequestEmailChange(ctx, auth.CurrentUser.AuthID, password, email); err != nil {
+				fmt.Println("Failed to change email:", err)
+			} else {
+				fmt.Println("A confirmation code was sent to", email+". Enter it with [4] to finish.")
+				time.Sleep(2000 * time.Millisecond)
+			}
+		case 4:
+			code := utils.ReadLine("Confirmation code: ")
+			if err := auth.ConfirmEmailChange(ctx, auth.CurrentUser.AuthID, code); err != nil {
+				fmt.Println("Failed to confirm email:", err)
+			} else {
+				fmt.Println("Email changed. Use it the next time you log in.")
+			}
+		case 5:
+			current, err := utils.ReadPasswordMasked("Current password: ")
+			if err != nil {
+				fmt.Println("Error reading password:", err)
+				break
+			}
+			next, err := ReadNewPassword("New password: ", p.Email, p.Username)
+			if err != nil {
+				fmt.Println("Error reading password:", err)
+				break
+			}
+			again, err := utils.ReadPasswordMasked("Repeat new password: ")
+			if err != nil {
+				fmt.Println("Error reading password:", err)
+				break
+			}
+			if next != again {
+				fmt.Println("Passwords do not match.")
+				break
+			}
+			if err := auth.ChangePassword(ctx, auth.CurrentUser.AuthID, current, next); err != nil {
+				fmt.Println("Failed to change password:", err)
+			} else {
+				fmt.Println("Password changed.")
+			}
+		case 6:
+			utils.ClearTerminal()
+			User_BillingAddress()
+			continue
+		case 7:
+			utils.ClearTerminal()
+			User_Currency()
+			continue
+		case 0:
+			utils.ClearTerminal()
+			return
+		}
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+	}
+}
+
+func User_Currency() {
+	ctx := context.Background()
+
+	cur, err := services.CustomerCurrency(ctx, auth.CurrentUser.CustomerID)
+	if err != nil {
+		fmt.Println("Error loading currency:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	list, err := services.ListCurrencies(ctx)
+	if err != nil {
+		fmt.Println("Error loading currencies:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	fmt.Println("\n=== CURRENCY ===")
+	fmt.Printf("Current: %s (%s)\n\n", cur.Code, cur.Symbol)
+	for _, c := range list {
+		fmt.Printf("- %s (%s)\n", c.Code, c.Symbol)
+	}
+
+	code := utils.ReadLine("\nNew currency code (empty = cancel): ")
+	if code == "" {
+		utils.ClearTerminal()
+		return
+	}
+
+	if err := services.SetCustomerCurrency(ctx, auth.CurrentUser.CustomerID, code); err != nil {
+		fmt.Println("Failed to change currency:", err)
+	} else {
+		fmt.Println("Currency updated.")
+	}
+	time.Sleep(1000 * time.Millisecond)
+	utils.ClearTerminal()
+}
+
+func User_BillingAddress() {
+	ctx := context.Background()
+
+	address, err := services.BillingAddress(ctx, auth.CurrentUser.CustomerID)
+	if err != nil {
+		fmt.Println("Error loading address:", err)
+		time.Sleep(1000 * time.Millisecond)
+		utils.ClearTerminal()
+		return
+	}
+
+	fmt.Println("\n=== BILLING ADDRESS ===")
+	if address == "" {
+		fmt.Println("Current: (not set, no tax is charged)")
+	} else {
+		fmt.Println("Current:", address)
+	}
+	fmt.Println("Tax is worked out from the last two parts: region, country.")
+
+	address = utils.ReadLine("\nNew address (empty = cancel): ")
+	if address == "" {
+		utils.ClearTerminal()
+		return
+	}
+
+	if err := services.SetBillingAddress(ctx, auth.CurrentUser.CustomerID, address); err != nil {
+		fmt.Println("Failed to save address:", err)
+	} else {
+		fmt.Println("Address saved.")
+	}
+	time.Sleep(1000 * time.Millisecond)
 	utils.ClearTerminal()
 }
@@ -0,0 +1,94 @@
+package jobs
+
+import (
+	"GamesProject/internal/services"
+	"context"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// logger writes job failures to JOBS_LOG (if set) so they don't
+// interrupt the interactive menus
+var logger = log.New(io.Discard, "[jobs] ", log.LstdFlags)
+
+// Start launches every background job in its own goroutine.
+// Each job runs once immediately and then on its interval until ctx is cancelled.
+func Start(ctx context.Context) {
+	if path := os.Getenv("JOBS_LOG"); path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err == nil {
+			logger.SetOutput(f)
+		}
+	}
+
+	all := []Job{
+		{
+			Name:     "release-games",
+			Interval: envDuration("RELEASE_JOB_INTERVAL", time.Minute),
+			Run:      services.ReleaseDueGames,
+		},
+		{
+			Name:     "discount-price-history",
+			Interval: envDuration("DISCOUNT_JOB_INTERVAL", time.Minute),
+			Run:      services.SyncDiscountPriceHistory,
+		},
+		{
+			Name:     "recommendations",
+			Interval: envDuration("RECOMMENDATION_JOB_INTERVAL", time.Hour),
+			Run:      services.RebuildRecommendations,
+		},
+		{
+			Name:     "expire-unpaid-orders",
+			Interval: envDuration("ORDER_EXPIRY_JOB_INTERVAL", time.Minute),
+			Run:      services.ExpireUnpaidOrders,
+		},
+		{
+			Name:     "lift-expired-suspensions",
+			Interval: envDuration("SUSPENSION_JOB_INTERVAL", time.Minute),
+			Run:      services.LiftExpiredSuspensions,
+		},
+	}
+
+	for _, j := range all {
+		go run(ctx, j)
+	}
+}
+
+func run(ctx context.Context, j Job) {
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.Run(ctx); err != nil {
+			logger.Printf("%s failed: %v", j.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// envDuration reads a duration like "30s" or "5m" from the environment
+func envDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
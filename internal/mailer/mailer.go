@@ -0,0 +1,159 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Mailer delivers plain-text emails
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// Default is the mailer used by Send. main sets it from the environment;
+// until then messages go to mail.log.
+var Default Mailer
+
+// defaultMailFile keeps codes off the terminal: printing a verification code
+// to whoever is at the keyboard would skip proving they own the address
+const defaultMailFile = "mail.log"
+
+func Send(ctx context.Context, to, subject, body string) error {
+	if Default == nil {
+		return LogMailer{Path: defaultMailFile}.Send(ctx, to, subject, body)
+	}
+	return Default.Send(ctx, to, subject, body)
+}
+
+/*
+FromEnv – picks the mailer from MAIL_DRIVER:
+  - smtp: SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME, SMTP_PASSWORD, MAIL_FROM
+  - stdout: prints each message, for local development only
+  - anything else appends to MAIL_FILE (default mail.log)
+*/
+func FromEnv() Mailer {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("MAIL_DRIVER"))) {
+	case "smtp":
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}
+	case "stdout":
+		return LogMailer{From: from}
+	default:
+		path := os.Getenv("MAIL_FILE")
+		if path == "" {
+			path = defaultMailFile
+		}
+		return LogMailer{Path: path, From: from}
+	}
+}
+
+// message builds an RFC 5322 message with CRLF line endings
+func message(from, to, subject, body string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// headerSafe rejects values that could inject extra headers
+func headerSafe(values ...string) error {
+	for _, v := range values {
+		if strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("invalid mail header value %q", v)
+		}
+	}
+	return nil
+}
+
+// SMTPMailer sends through an SMTP server, upgrading to TLS when the server offers STARTTLS
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m SMTPMailer) Send(ctx context.Context, to, subject, body string) error {
+	if m.Host == "" {
+		return fmt.Errorf("SMTP_HOST is not set")
+	}
+	if err := headerSafe(to, subject); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	// smtp.SendMail has no context, so run it aside and give up when ctx ends
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, []string{to}, message(m.From, to, subject, body))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LogMailer is for development: it appends each message to Path, or prints it when Path is empty
+type LogMailer struct {
+	Path string
+	From string
+}
+
+func (m LogMailer) Send(ctx context.Context, to, subject, body string) error {
+	if err := headerSafe(to, subject); err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if m.Path != "" {
+		f, err := os.OpenFile(m.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	from := m.From
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+
+	msg := strings.ReplaceAll(string(message(from, to, subject, body)), "\r\n", "\n")
+	_, err := fmt.Fprintf(w, "\n----- MAIL -----\n%s----------------\n", msg)
+	return err
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditEntry struct {
+	AuditID     int64
+	ActorAuthID *int
+	ActorEmail  *string
+	Action      string
+	TargetType  *string
+	TargetID    *int
+	Before      *string // JSON
+	After       *string // JSON
+	CreatedAt   time.Time
+}
+
+// AuditFilter narrows SearchAuditLog; zero values match everything
+type AuditFilter struct {
+	ActorAuthID int
+	Action      string // literal prefix ignoring case, e.g. "user." matches user.ban and user.unban
+	From        *time.Time
+	To          *time.Time // exclusive
+	Limit       int
+}
+
+/*
+auditTables lists the rows that can be snapshotted for the audit log, by key
+column, with the columns that must never be copied into it
+*/
+var auditTables = map[string]struct {
+	key  string
+	omit []string
+}{
+	"userauth":   {key: "authid", omit: []string{"passwordhash"}},
+	"developers": {key: "authid"},
+	"games":      {key: "gameid"},
+	"genres":     {key: "genreid"},
+	"currencies": {key: "currencycode"},
+	"tax_rates":  {key: "taxrateid"},
+	"tags":       {key: "tagid"},
+	"reviews":    {key: "reviewid"},
+}
+
+/*
+AuditSnapshot returns the row as JSON, or nil if it doesn't exist. It reads
+inside the transaction that makes the change, so the snapshots and the log
+entry agree with what was committed.
+*/
+func AuditSnapshot(ctx context.Context, tx pgx.Tx, table string, id any) (json.RawMessage, error) {
+	t, ok := auditTables[table]
+	if !ok {
+		return nil, fmt.Errorf("no audit snapshot for table %q", table)
+	}
+
+	var data []byte
+	err := tx.QueryRow(ctx,
+		fmt.Sprintf(`SELECT to_jsonb(t) - $2::text[] FROM %s t WHERE t.%s = $1`, table, t.key),
+		id, append([]string{}, t.omit...), // never NULL, which would blank the row
+	).Scan(&data)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(data), nil
+}
+
+/*
+WriteAuditLog – appends one entry in the transaction that made the change, so
+the change never commits without it. before and after are stored as JSON
+snapshots of the target; pass nil when there is nothing to record.
+actorAuthID 0 records the system (e.g. a background job) as the actor;
+targetID 0 a target without a numeric ID (e.g. a currency).
+*/
+func WriteAuditLog(ctx context.Context, tx pgx.Tx, actorAuthID int, action, targetType string, targetID int, before, after any) error {
+	b, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	a, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO audit_log (actorauthid, action, targettype, targetid, before, after)
+		 VALUES (NULLIF($1, 0), $2, $3, NULLIF($4, 0), $5, $6)`,
+		actorAuthID, action, targetType, targetID, b, a,
+	)
+	return err
+}
+
+func auditJSON(v any) (*string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if raw, ok := v.(json.RawMessage); ok && raw == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := string(data)
+	return &s, nil
+}
+
+func SearchAuditLog(ctx context.Context, db *pgxpool.Pool, f AuditFilter) ([]AuditEntry, error) {
+	query := `
+        SELECT al.auditid, al.actorauthid, ua.email, al.action, al.targettype, al.targetid,
+               al.before::text, al.after::text, al.created_at
+        FROM audit_log al
+        LEFT JOIN userauth ua ON ua.authid = al.actorauthid
+        WHERE ($1 = 0 OR al.actorauthid = $1)
+          AND ($2 = '' OR starts_with(lower(al.action), lower($2)))
+          AND ($3::timestamp IS NULL OR al.created_at >= $3)
+          AND ($4::timestamp IS NULL OR al.created_at < $4)
+        ORDER BY al.created_at DESC, al.auditid DESC
+        LIMIT NULLIF($5, 0);
+    `
+
+	rows, err := db.Query(ctx, query, f.ActorAuthID, f.Action, f.From, f.To, f.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		if err := rows.Scan(&e.AuditID, &e.ActorAuthID, &e.ActorEmail, &e.Action, &e.TargetType, &e.TargetID,
+			&e.Before, &e.After, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSearchAuditLogActionIsALiteralPrefix(t *testing.T) {
+	db := testPool(t)
+	ctx := context.Background()
+
+	// audit_log is append-only, so every run uses its own action names
+	base := uniqueName("t")
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+	for _, action := range []string{base + "_x.one", base + "Zx.two", base + "%x.three"} {
+		if err := WriteAuditLog(ctx, tx, 0, action, "test", 0, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{base + "_", []string{base + "_x.one"}},
+		{base + "%", []string{base + "%x.three"}},
+		{base + "zX", []string{base + "Zx.two"}}, // case is ignored
+		{base + "x", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := SearchAuditLog(ctx, db, AuditFilter{Action: tt.prefix})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actions []string
+		for _, e := range got {
+			actions = append(actions, e.Action)
+		}
+		if len(actions) != len(tt.want) || (len(actions) > 0 && actions[0] != tt.want[0]) {
+			t.Errorf("prefix %q matched %q, want %q", tt.prefix, actions, tt.want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxTokenAttempts is how many wrong codes an outstanding token survives
+const maxTokenAttempts = 5
+
+var ErrInvalidToken = errors.New("invalid or expired code")
+
+/*
+CreateAuthToken – stores a single-use token by its hash. Older unused tokens for
+the same purpose are expired so only the latest one works.
+*/
+func CreateAuthToken(ctx context.Context, db *pgxpool.Pool, authID int, purpose, tokenHash string, newEmail *string, ttl time.Duration) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`UPDATE auth_tokens SET expires_at = NOW()
+		 WHERE authid = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()`,
+		authID, purpose,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO auth_tokens (authid, purpose, tokenhash, newemail, expires_at)
+		 VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))`,
+		authID, purpose, tokenHash, newEmail, ttl.Seconds(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetPendingEmailChange returns the address waiting for confirmation, or nil
+func GetPendingEmailChange(ctx context.Context, db *pgxpool.Pool, authID int) (*string, error) {
+	var email string
+	err := db.QueryRow(ctx,
+		`SELECT newemail FROM auth_tokens
+		 WHERE authid = $1
+		   AND purpose = 'email_change'
+		   AND used_at IS NULL
+		   AND expires_at > NOW()
+		   AND attempts < $2
+		 ORDER BY created_at DESC
+		 LIMIT 1`,
+		authID, maxTokenAttempts,
+	).Scan(&email)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &email, nil
+}
+
+/*
+consumeToken – marks the matching live token used inside tx and returns its newemail.
+On a miss the live token's attempt count goes up outside tx, so guessing burns it.
+*/
+func consumeToken(ctx context.Context, db *pgxpool.Pool, tx pgx.Tx, authID int, purpose, tokenHash string) (*string, error) {
+	var newEmail *string
+	err := tx.QueryRow(ctx,
+		`UPDATE auth_tokens SET used_at = NOW()
+		 WHERE authid = $1
+		   AND purpose = $2
+		   AND tokenhash = $3
+		   AND used_at IS NULL
+		   AND expires_at > NOW()
+		   AND attempts < $4
+		 RETURNING newemail`,
+		authID, purpose, tokenHash, maxTokenAttempts,
+	).Scan(&newEmail)
+	if err == pgx.ErrNoRows {
+		_, _ = db.Exec(ctx,
+			`UPDATE auth_tokens SET attempts = attempts + 1
+			 WHERE authid = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()`,
+			authID, purpose,
+		)
+		return nil, ErrInvalidToken
+	}
+	return newEmail, err
+}
+
+/*
+ConfirmEmailChange – uses up the token and moves the account to the new address.
+userauth and customers are updated together so login and receipts never disagree.
+*/
+func ConfirmEmailChange(ctx context.Context, db *pgxpool.Pool, authID int, tokenHash string) (string, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	newEmail, err := consumeToken(ctx, db, tx, authID, "email_change", tokenHash)
+	if err != nil {
+		return "", err
+	}
+	if newEmail == nil {
+		return "", ErrInvalidToken
+	}
+	email := *newEmail
+
+	// the code reached the new address, so it counts as verified
+	_, err = tx.Exec(ctx, `UPDATE userauth SET email = $1, email_verified_at = NOW() WHERE authid = $2`, email, authID)
+	if err == nil {
+		_, err = tx.Exec(ctx, `UPDATE customers SET email = $1 WHERE authid = $2`, email, authID)
+	}
+	if isUniqueViolation(err) {
+		return "", errors.New("email is already in use")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return email, tx.Commit(ctx)
+}
+
+func VerifyEmail(ctx context.Context, db *pgxpool.Pool, authID int, tokenHash string) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := consumeToken(ctx, db, tx, authID, "verify_email", tokenHash); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE userauth SET email_verified_at = NOW() WHERE authid = $1`, authID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+/*
+ResetPassword – sets the new hash if the reset code is good. Receiving the
+code also proves the email, so an unverified account becomes verified, and
+a login lockout is lifted along with the failures that led to it.
+*/
+func ResetPassword(ctx context.Context, db *pgxpool.Pool, authID int, tokenHash, passwordHash string) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := consumeToken(ctx, db, tx, authID, "password_reset", tokenHash); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE userauth
+		 SET passwordhash = $1,
+		     email_verified_at = COALESCE(email_verified_at, NOW()),
+		     locked_until = NOW()
+		 WHERE authid = $2`,
+		passwordHash, authID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
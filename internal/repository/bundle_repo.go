@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BundleItem struct {
+	GameID int
+	Title  string
+	Price  float64 // effective price with the bundle discount applied
+}
+
+type Bundle struct {
+	BundleID        int
+	Title           string
+	DeveloperName   string
+	DiscountPercent int
+	Price           float64 // sum of the bundled item prices
+	FullPrice       float64 // what the games would cost separately
+	Items           []BundleItem
+}
+
+// bundleItemPriceSQL is a bundled game's effective price minus the bundle discount
+const bundleItemPriceSQL = `ROUND(` + effectivePriceSQL + ` * (100 - b.discountpercent) / 100, 2)`
+
+func CreateBundle(ctx context.Context, db *pgxpool.Pool, devID int, title string, discountPercent int, gameIDs []int) (int, error) {
+	// the same game listed twice is still one game
+	seen := map[int]bool{}
+	var unique []int
+	for _, gid := range gameIDs {
+		if !seen[gid] {
+			seen[gid] = true
+			unique = append(unique, gid)
+		}
+	}
+	gameIDs = unique
+
+	if len(gameIDs) < 2 {
+		return 0, errors.New("a bundle needs at least two games")
+	}
+
+	for _, gid := range gameIDs {
+		owned, err := IsGameOwnedByDeveloper(ctx, db, devID, gid)
+		if err != nil {
+			return 0, err
+		}
+		if !owned {
+			return 0, errors.New("permission denied: you can only bundle your own games")
+		}
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	err = tx.QueryRow(ctx,
+		`INSERT INTO bundles (developerid, title, discountpercent)
+		 VALUES ($1, $2, $3)
+		 RETURNING bundleid`,
+		devID, title, discountPercent,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, gid := range gameIDs {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO bundleitems (bundleid, gameid)
+			 VALUES ($1, $2)
+			 ON CONFLICT (bundleid, gameid) DO NOTHING`,
+			id, gid,
+		); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit(ctx)
+}
+
+func RemoveBundle(ctx context.Context, db *pgxpool.Pool, devID, bundleID int) error {
+	tag, err := db.Exec(ctx,
+		`UPDATE bundles
+		 SET deleted_at = NOW()
+		 WHERE bundleid = $1
+		   AND developerid = $2
+		   AND deleted_at IS NULL`,
+		bundleID, devID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("bundle not found")
+	}
+	return nil
+}
+
+/*
+GetBundles – all live bundles, or only one developer's when devID is non-nil.
+Bundles that contain a removed game are skipped, and so are those with a game
+hidden by a suspension until it is lifted.
+*/
+func GetBundles(ctx context.Context, db *pgxpool.Pool, devID *int) ([]Bundle, error) {
+	query := `
+        SELECT b.bundleid
+        FROM bundles b
+        WHERE b.deleted_at IS NULL
+          AND ($1::int IS NULL OR b.developerid = $1)
+          AND NOT EXISTS (
+              SELECT 1 FROM bundleitems bi
+              JOIN games g ON g.gameid = bi.gameid
+              WHERE bi.bundleid = b.bundleid
+                AND (g.deleted_at IS NOT NULL OR g.hidden_at IS NOT NULL)
+          )
+        ORDER BY b.bundleid;
+    `
+
+	rows, err := db.Query(ctx, query, devID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	list := []Bundle{}
+	for _, id := range ids {
+		b, err := GetBundle(ctx, db, id)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *b)
+	}
+
+	return list, nil
+}
+
+func GetBundle(ctx context.Context, db *pgxpool.Pool, bundleID int) (*Bundle, error) {
+	var b Bundle
+
+	err := db.QueryRow(ctx,
+		`SELECT b.bundleid, b.title, d.developername, b.discountpercent
+		 FROM bundles b
+		 JOIN developers d ON d.developerid = b.developerid
+		 WHERE b.bundleid = $1
+		   AND b.deleted_at IS NULL
+		   AND NOT EXISTS (
+		       SELECT 1 FROM bundleitems bi
+		       JOIN games g ON g.gameid = bi.gameid
+		       WHERE bi.bundleid = b.bundleid AND g.hidden_at IS NOT NULL
+		   )`,
+		bundleID,
+	).Scan(&b.BundleID, &b.Title, &b.DeveloperName, &b.DiscountPercent)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New("bundle not found")
+		}
+		return nil, err
+	}
+
+	query := `
+        SELECT g.gameid, g.title, ` + bundleItemPriceSQL + `, ` + effectivePriceSQL + `
+        FROM bundleitems bi
+        JOIN bundles b ON b.bundleid = bi.bundleid
+        JOIN games g ON g.gameid = bi.gameid` + activeDiscountJoin + `
+        WHERE bi.bundleid = $1
+          AND g.deleted_at IS NULL
+        ORDER BY g.gameid;
+    `
+
+	rows, err := db.Query(ctx, query, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item BundleItem
+		var full float64
+		if err := rows.Scan(&item.GameID, &item.Title, &item.Price, &full); err != nil {
+			return nil, err
+		}
+		b.Items = append(b.Items, item)
+		b.Price += item.Price
+		b.FullPrice += full
+	}
+
+	return &b, rows.Err()
+}
+
+/*
+SetBaseGame – marks a game as DLC of baseID, or as standalone when baseID is nil.
+Both games must belong to the developer, and DLC stays one level deep: the base
+can't be a DLC and the game can't have DLC of its own.
+*/
+func SetBaseGame(ctx context.Context, db *pgxpool.Pool, devID, gameID int, baseID *int) error {
+	owned, err := IsGameOwnedByDeveloper(ctx, db, devID, gameID)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return errors.New("permission denied: you can only edit your own games")
+	}
+
+	if baseID != nil {
+		if *baseID == gameID {
+			return errors.New("a game cannot be its own base game")
+		}
+
+		owned, err := IsGameOwnedByDeveloper(ctx, db, devID, *baseID)
+		if err != nil {
+			return err
+		}
+		if !owned {
+			return errors.New("base game must be one of your games")
+		}
+
+		baseOfBase, err := GetBaseGameID(ctx, db, *baseID)
+		if err != nil {
+			return err
+		}
+		if baseOfBase != nil {
+			return errors.New("base game cannot itself be a DLC")
+		}
+
+		// DLC is one level deep, so a game with DLC of its own can't become one
+		var hasDLC bool
+		err = db.QueryRow(ctx,
+			`SELECT EXISTS(
+				SELECT 1 FROM games WHERE basegameid = $1 AND deleted_at IS NULL
+			)`,
+			gameID,
+		).Scan(&hasDLC)
+		if err != nil {
+			return err
+		}
+		if hasDLC {
+			return errors.New("this game has DLC of its own, so it cannot be a DLC")
+		}
+	}
+
+	_, err = db.Exec(ctx,
+		`UPDATE games SET basegameid = $1 WHERE gameid = $2`,
+		baseID, gameID,
+	)
+	return err
+}
+
+// GetBaseGameID returns the base game of a DLC, nil for standalone games
+func GetBaseGameID(ctx context.Context, db *pgxpool.Pool, gameID int) (*int, error) {
+	var baseID *int
+	err := db.QueryRow(ctx,
+		`SELECT basegameid FROM games WHERE gameid = $1`,
+		gameID,
+	).Scan(&baseID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New("game not found")
+		}
+		return nil, err
+	}
+	return baseID, nil
+}
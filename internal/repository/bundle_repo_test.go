@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetBaseGameKeepsDLCOneLevelDeep(t *testing.T) {
+	db := testPool(t)
+	ctx := context.Background()
+
+	base := createTestGame(t, db)
+	var devID int
+	if err := db.QueryRow(ctx, `SELECT developerid FROM games WHERE gameid = $1`, base).Scan(&devID); err != nil {
+		t.Fatal(err)
+	}
+	var dlc, other int
+	for _, id := range []*int{&dlc, &other} {
+		if err := db.QueryRow(ctx,
+			`INSERT INTO games (developerid, title, price, releasedate, released_at)
+			 VALUES ($1, $2, 5, CURRENT_DATE, NOW()) RETURNING gameid`,
+			devID, uniqueName("game"),
+		).Scan(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := SetBaseGame(ctx, db, devID, dlc, &base); err != nil {
+		t.Fatalf("make dlc: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		gameID, base int
+	}{
+		{"base of a DLC", other, dlc},      // other -> dlc -> base
+		{"game that has DLC", base, other}, // dlc -> base -> other
+	}
+	for _, tt := range tests {
+		if err := SetBaseGame(ctx, db, devID, tt.gameID, &tt.base); err == nil {
+			t.Errorf("%s: a two-level DLC chain was accepted", tt.name)
+		}
+	}
+
+	if _, err := CreateBundle(ctx, db, devID, "Twice", 10, []int{base, base}); err == nil {
+		t.Error("a bundle of one game listed twice was accepted")
+	}
+	id, err := CreateBundle(ctx, db, devID, "Pair", 10, []int{base, other, base})
+	if err != nil {
+		t.Fatalf("bundle with a repeated game: %v", err)
+	}
+	b, err := GetBundle(ctx, db, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Items) != 2 {
+		t.Errorf("bundle has %d items, want 2", len(b.Items))
+	}
+}
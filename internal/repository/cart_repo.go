@@ -2,16 +2,41 @@ package repository
 
 import (
 	"context"
+	"errors"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrCartItemNotFound is returned when an order item is not in the caller's
+// open cart, whether it doesn't exist or belongs to someone else
+var ErrCartItemNotFound = errors.New("cart item not found")
+
+// OrderTotals is what checkout stamps onto an order. ItemTax maps orderitemid to its tax.
+type OrderTotals struct {
+	Total        float64
+	CurrencyCode *string
+	ExchangeRate float64
+	TaxRate      float64
+	TaxInclusive bool
+	TaxTotal     float64
+	ItemTax      map[int]float64
+
+	// set when the order is bought as a gift
+	GiftRecipientID *int
+	GiftMessage     *string
+}
+
 type CartItem struct {
 	OrderItemID     int
 	GameID          int
 	Title           string
 	Quantity        int
 	PriceAtPurchase float64
+	BundleID        *int // set when the item came from a bundle
+	BundleTitle     *string
+	MaxPerOrder     int
+	Unavailable     bool // the game or its bundle was removed from the store
 }
 
 type Cart struct {
@@ -20,25 +45,42 @@ type Cart struct {
 	Total   float64
 }
 
-/*
-GetActiveCart – creates an empty cart if not exists
-*/
-func GetActiveCart(ctx context.Context, db *pgxpool.Pool, customerID int) (int, error) {
+// FindActiveCart returns nil when the customer has no open cart, without creating one
+func FindActiveCart(ctx context.Context, db *pgxpool.Pool, customerID int) (*int, error) {
 	var orderID int
 
 	query := `
         SELECT orderid 
         FROM orders
         WHERE customerid = $1
-          AND totalprice = 0
+          AND status = 'cart'
           AND deleted_at IS NULL
+        ORDER BY orderid DESC
         LIMIT 1;
     `
 	err := db.QueryRow(ctx, query, customerID).Scan(&orderID)
-	if err == nil {
-		return orderID, nil
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
 	}
+	return &orderID, nil
+}
 
+/*
+GetActiveCart – creates an empty cart if not exists
+*/
+func GetActiveCart(ctx context.Context, db *pgxpool.Pool, customerID int) (int, error) {
+	found, err := FindActiveCart(ctx, db, customerID)
+	if err != nil {
+		return 0, err
+	}
+	if found != nil {
+		return *found, nil
+	}
+
+	var orderID int
 	// create new cart
 	insert := `
         INSERT INTO orders (customerid, totalprice)
@@ -50,17 +92,169 @@ func GetActiveCart(ctx context.Context, db *pgxpool.Pool, customerID int) (int,
 }
 
 /*
-AddItemToCart
+AddItemToCart – listPrice is the undiscounted price, kept so order details can
+show the discount. bundleID is nil for games bought on their own; those are merged
+into an existing line for the same game, refreshed to the given price.
 */
-func AddItemToCart(ctx context.Context, db *pgxpool.Pool, orderID, gameID, qty int, price float64) error {
+func AddItemToCart(ctx context.Context, db *pgxpool.Pool, orderID, gameID, qty int, price, listPrice float64, bundleID *int) error {
+	if bundleID == nil {
+		tag, err := db.Exec(ctx,
+			`UPDATE orderitems
+			 SET quantity = quantity + $3, priceatpurchase = $4, listprice = $5
+			 WHERE orderid = $1
+			   AND gameid = $2
+			   AND bundleid IS NULL
+			   AND deleted_at IS NULL`,
+			orderID, gameID, qty, price, listPrice,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() > 0 {
+			return nil
+		}
+	}
+
 	query := `
-        INSERT INTO orderitems (orderid, gameid, quantity, priceatpurchase)
-        VALUES ($1, $2, $3, $4);
+        INSERT INTO orderitems (orderid, gameid, quantity, priceatpurchase, listprice, bundleid)
+        VALUES ($1, $2, $3, $4, $5, $6);
     `
-	_, err := db.Exec(ctx, query, orderID, gameID, qty, price)
+	_, err := db.Exec(ctx, query, orderID, gameID, qty, price, listPrice, bundleID)
 	return err
 }
 
+// BundleLine is one game of a bundle as it goes into the cart
+type BundleLine struct {
+	GameID    int
+	Price     float64
+	ListPrice float64
+}
+
+// AddBundleLines adds a bundle's games to the cart together, or none of them
+func AddBundleLines(ctx context.Context, db *pgxpool.Pool, orderID, bundleID int, lines []BundleLine) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, l := range lines {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO orderitems (orderid, gameid, quantity, priceatpurchase, listprice, bundleid)
+			 VALUES ($1, $2, 1, $3, $4, $5)`,
+			orderID, l.GameID, l.Price, l.ListPrice, bundleID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+/*
+MergeDuplicateCartLines – folds repeated non-bundle lines for the same game
+(left by older carts) into the earliest line
+*/
+func MergeDuplicateCartLines(ctx context.Context, db *pgxpool.Pool, orderID int) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`UPDATE orderitems keep
+		 SET quantity = dup.total
+		 FROM (
+		     SELECT MIN(orderitemid) AS keepid, SUM(quantity) AS total
+		     FROM orderitems
+		     WHERE orderid = $1 AND bundleid IS NULL AND deleted_at IS NULL
+		     GROUP BY gameid
+		     HAVING COUNT(*) > 1
+		 ) dup
+		 WHERE keep.orderitemid = dup.keepid`,
+		orderID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE orderitems oi
+		 SET deleted_at = NOW()
+		 WHERE oi.orderid = $1
+		   AND oi.bundleid IS NULL
+		   AND oi.deleted_at IS NULL
+		   AND oi.orderitemid > (
+		       SELECT MIN(o2.orderitemid)
+		       FROM orderitems o2
+		       WHERE o2.orderid = oi.orderid
+		         AND o2.gameid = oi.gameid
+		         AND o2.bundleid IS NULL
+		         AND o2.deleted_at IS NULL
+		   )`,
+		orderID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CartGameQuantity is how many copies of a game the cart holds across all lines
+func CartGameQuantity(ctx context.Context, db *pgxpool.Pool, orderID, gameID int) (int, error) {
+	var qty int
+	err := db.QueryRow(ctx,
+		`SELECT COALESCE(SUM(quantity), 0)
+		 FROM orderitems
+		 WHERE orderid = $1 AND gameid = $2 AND deleted_at IS NULL`,
+		orderID, gameID,
+	).Scan(&qty)
+	return qty, err
+}
+
+/*
+UpdateCartItemPrice – used once the customer has accepted a price change
+*/
+func UpdateCartItemPrice(ctx context.Context, db *pgxpool.Pool, customerID, orderItemID int, price, listPrice float64) error {
+	tag, err := db.Exec(ctx,
+		`UPDATE orderitems oi
+		 SET priceatpurchase = $1, listprice = $2
+		 FROM orders o
+		 WHERE o.orderid = oi.orderid
+		   AND oi.orderitemid = $3
+		   AND oi.deleted_at IS NULL
+		   AND o.customerid = $4
+		   AND o.status = 'cart'
+		   AND o.deleted_at IS NULL`,
+		price, listPrice, orderItemID, customerID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCartItemNotFound
+	}
+	return nil
+}
+
+/*
+CartHasGame
+*/
+func CartHasGame(ctx context.Context, db *pgxpool.Pool, orderID, gameID int) (bool, error) {
+	var exists bool
+	err := db.QueryRow(ctx,
+		`SELECT EXISTS(
+			SELECT 1 FROM orderitems
+			WHERE orderid = $1 AND gameid = $2 AND deleted_at IS NULL
+		)`,
+		orderID, gameID,
+	).Scan(&exists)
+	return exists, err
+}
+
 /*
 GetCartItems
 */
@@ -71,9 +265,14 @@ func GetCartItems(ctx context.Context, db *pgxpool.Pool, orderID int) ([]CartIte
             oi.gameid,
             g.title,
             oi.quantity,
-            oi.priceatpurchase
+            oi.priceatpurchase,
+            oi.bundleid,
+            b.title,
+            g.maxperorder,
+            (g.deleted_at IS NOT NULL OR g.hidden_at IS NOT NULL OR b.deleted_at IS NOT NULL)
         FROM orderitems oi
         JOIN games g ON g.gameid = oi.gameid
+        LEFT JOIN bundles b ON b.bundleid = oi.bundleid
         WHERE oi.orderid = $1
           AND oi.deleted_at IS NULL;
     `
@@ -89,7 +288,7 @@ func GetCartItems(ctx context.Context, db *pgxpool.Pool, orderID int) ([]CartIte
 
 	for rows.Next() {
 		var ci CartItem
-		if err := rows.Scan(&ci.OrderItemID, &ci.GameID, &ci.Title, &ci.Quantity, &ci.PriceAtPurchase); err != nil {
+		if err := rows.Scan(&ci.OrderItemID, &ci.GameID, &ci.Title, &ci.Quantity, &ci.PriceAtPurchase, &ci.BundleID, &ci.BundleTitle, &ci.MaxPerOrder, &ci.Unavailable); err != nil {
 			return nil, 0, err
 		}
 		items = append(items, ci)
@@ -100,30 +299,67 @@ func GetCartItems(ctx context.Context, db *pgxpool.Pool, orderID int) ([]CartIte
 }
 
 /*
-UpdateCartItemQty
+UpdateCartItemQty – only touches items in the customer's open cart
 */
-func UpdateCartItemQty(ctx context.Context, db *pgxpool.Pool, orderItemID, qty int) error {
+func UpdateCartItemQty(ctx context.Context, db *pgxpool.Pool, customerID, orderItemID, qty int) error {
 	query := `
-        UPDATE orderitems
+        UPDATE orderitems oi
         SET quantity = $1
-        WHERE orderitemid = $2
-          AND deleted_at IS NULL;
+        FROM orders o
+        WHERE o.orderid = oi.orderid
+          AND oi.orderitemid = $2
+          AND oi.deleted_at IS NULL
+          AND o.customerid = $3
+          AND o.status = 'cart'
+          AND o.deleted_at IS NULL;
     `
-	_, err := db.Exec(ctx, query, qty, orderItemID)
-	return err
+	tag, err := db.Exec(ctx, query, qty, orderItemID, customerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCartItemNotFound
+	}
+	return nil
 }
 
 /*
-RemoveCartItem
+RemoveCartItem – removing a bundled item removes the rest of its bundle too,
+since the bundle discount only applies to the full set. Only items in the
+customer's open cart can be removed.
 */
-func RemoveCartItem(ctx context.Context, db *pgxpool.Pool, orderItemID int) error {
+func RemoveCartItem(ctx context.Context, db *pgxpool.Pool, customerID, orderItemID int) error {
 	query := `
         UPDATE orderitems
         SET deleted_at = NOW()
-        WHERE orderitemid = $1;
+        WHERE deleted_at IS NULL
+          AND orderid = (
+              SELECT oi.orderid
+              FROM orderitems oi
+              JOIN orders o ON o.orderid = oi.orderid
+              WHERE oi.orderitemid = $1
+                AND oi.deleted_at IS NULL
+                AND o.customerid = $2
+                AND o.status = 'cart'
+                AND o.deleted_at IS NULL
+          )
+          AND (
+              orderitemid = $1
+              OR bundleid = (
+                  SELECT bundleid
+                  FROM orderitems
+                  WHERE orderitemid = $1
+              )
+          );
     `
-	_, err := db.Exec(ctx, query, orderItemID)
-	return err
+	tag, err := db.Exec(ctx, query, orderItemID, customerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCartItemNotFound
+	}
+	return nil
 }
 
 /*
@@ -140,15 +376,58 @@ func ClearCart(ctx context.Context, db *pgxpool.Pool, orderID int) error {
 }
 
 /*
-Checkout – finalizes the order and sets total price
+Checkout – finalizes the order: per-line tax, then total price, currency and tax totals.
+The order moves from cart to pending until it is paid or cancelled.
 */
-func Checkout(ctx context.Context, db *pgxpool.Pool, orderID int, total float64) error {
+func Checkout(ctx context.Context, db *pgxpool.Pool, orderID int, t OrderTotals) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for itemID, tax := range t.ItemTax {
+		_, err := tx.Exec(ctx,
+			`UPDATE orderitems SET taxamount = $1 WHERE orderitemid = $2 AND orderid = $3`,
+			tax, itemID, orderID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
 	query := `
         UPDATE orders
-        SET totalprice = $1
+        SET totalprice = $1,
+            currencycode = $3,
+            exchangerate = $4,
+            taxrate = $5,
+            taxinclusive = $6,
+            taxtotal = $7,
+            status = 'pending',
+            checkedout_at = NOW()
         WHERE orderid = $2
+          AND status = 'cart'
           AND deleted_at IS NULL;
     `
-	_, err := db.Exec(ctx, query, total, orderID)
-	return err
+	tag, err := tx.Exec(ctx, query, t.Total, orderID, t.CurrencyCode, t.ExchangeRate, t.TaxRate, t.TaxInclusive, t.TaxTotal)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("cart was already checked out")
+	}
+
+	if t.GiftRecipientID != nil {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO gifts (orderid, sendercustomerid, recipientcustomerid, message)
+			 SELECT orderid, customerid, $2, $3 FROM orders WHERE orderid = $1`,
+			orderID, *t.GiftRecipientID, t.GiftMessage,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
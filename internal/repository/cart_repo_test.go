@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCartItemsAreScopedToTheirCustomer(t *testing.T) {
+	db := testPool(t)
+	ctx := context.Background()
+
+	alice := createTestCustomer(t, db)
+	bob := createTestCustomer(t, db)
+	gameID := createTestGame(t, db)
+
+	cartID, err := GetActiveCart(ctx, db, alice)
+	if err != nil {
+		t.Fatalf("GetActiveCart: %v", err)
+	}
+	if err := AddItemToCart(ctx, db, cartID, gameID, 1, 10, 10, nil); err != nil {
+		t.Fatalf("AddItemToCart: %v", err)
+	}
+	items, _, err := GetCartItems(ctx, db, cartID)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("GetCartItems = %v, %v; want one item", items, err)
+	}
+	line := items[0].OrderItemID
+
+	// a line that belongs to an order that has left the cart
+	var pendingLine int
+	err = db.QueryRow(ctx,
+		`WITH o AS (
+		     INSERT INTO orders (customerid, totalprice, status) VALUES ($1, 10, 'pending') RETURNING orderid
+		 )
+		 INSERT INTO orderitems (orderid, gameid, quantity, priceatpurchase)
+		 SELECT orderid, $2, 1, 10 FROM o
+		 RETURNING orderitemid`,
+		alice, gameID,
+	).Scan(&pendingLine)
+	if err != nil {
+		t.Fatalf("insert pending order: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"other customer updates quantity", func() error { return UpdateCartItemQty(ctx, db, bob, line, 5) }},
+		{"other customer updates price", func() error { return UpdateCartItemPrice(ctx, db, bob, line, 1, 1) }},
+		{"other customer removes line", func() error { return RemoveCartItem(ctx, db, bob, line) }},
+		{"owner updates quantity of a non-cart line", func() error { return UpdateCartItemQty(ctx, db, alice, pendingLine, 5) }},
+		{"owner updates price of a non-cart line", func() error { return UpdateCartItemPrice(ctx, db, alice, pendingLine, 1, 1) }},
+		{"owner removes a non-cart line", func() error { return RemoveCartItem(ctx, db, alice, pendingLine) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, ErrCartItemNotFound) {
+				t.Fatalf("err = %v, want ErrCartItemNotFound", err)
+			}
+		})
+	}
+
+	// nothing above may have changed either line
+	var qty int
+	var price float64
+	var deleted bool
+	for _, id := range []int{line, pendingLine} {
+		err := db.QueryRow(ctx,
+			`SELECT quantity, priceatpurchase, deleted_at IS NOT NULL FROM orderitems WHERE orderitemid = $1`,
+			id,
+		).Scan(&qty, &price, &deleted)
+		if err != nil {
+			t.Fatalf("read line %d: %v", id, err)
+		}
+		if qty != 1 || price != 10 || deleted {
+			t.Errorf("line %d = qty %d, price %.2f, deleted %v; want untouched", id, qty, price, deleted)
+		}
+	}
+
+	// the owner can still change their own cart
+	if err := UpdateCartItemQty(ctx, db, alice, line, 2); err != nil {
+		t.Errorf("owner UpdateCartItemQty: %v", err)
+	}
+	if err := RemoveCartItem(ctx, db, alice, line); err != nil {
+		t.Errorf("owner RemoveCartItem: %v", err)
+	}
+}
+
+func TestFindActiveCartDoesNotCreateOne(t *testing.T) {
+	db := testPool(t)
+	ctx := context.Background()
+	customerID := createTestCustomer(t, db)
+
+	found, err := FindActiveCart(ctx, db, customerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found != nil {
+		t.Fatalf("new customer has cart %d", *found)
+	}
+	var orders int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM orders WHERE customerid = $1`, customerID).Scan(&orders); err != nil {
+		t.Fatal(err)
+	}
+	if orders != 0 {
+		t.Errorf("looking up the cart created %d order(s)", orders)
+	}
+
+	created, err := GetActiveCart(ctx, db, customerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err = FindActiveCart(ctx, db, customerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == nil || *found != created {
+		t.Errorf("FindActiveCart = %v, want the cart %d GetActiveCart opened", found, created)
+	}
+}
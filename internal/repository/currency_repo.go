@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Currency – ExchangeRate is how many units of this currency one unit of the base currency buys
+type Currency struct {
+	Code         string
+	Symbol       string
+	ExchangeRate float64
+	UpdatedAt    time.Time
+}
+
+type RegionalPrice struct {
+	CurrencyCode string
+	Symbol       string
+	Price        float64
+}
+
+func GetCurrencies(ctx context.Context, db *pgxpool.Pool) ([]Currency, error) {
+	rows, err := db.Query(ctx,
+		`SELECT currencycode, symbol, exchangerate, updated_at
+		 FROM currencies
+		 ORDER BY currencycode`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Currency{}
+	for rows.Next() {
+		var c Currency
+		if err := rows.Scan(&c.Code, &c.Symbol, &c.ExchangeRate, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+
+	return list, rows.Err()
+}
+
+func GetCurrency(ctx context.Context, db *pgxpool.Pool, code string) (*Currency, error) {
+	var c Currency
+	err := db.QueryRow(ctx,
+		`SELECT currencycode, symbol, exchangerate, updated_at
+		 FROM currencies
+		 WHERE currencycode = $1`,
+		code,
+	).Scan(&c.Code, &c.Symbol, &c.ExchangeRate, &c.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New("currency not found")
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+/*
+UpsertCurrency – adds a currency or updates its symbol and exchange rate
+*/
+func UpsertCurrency(ctx context.Context, tx pgx.Tx, code, symbol string, rate float64) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO currencies (currencycode, symbol, exchangerate)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (currencycode)
+		 DO UPDATE SET symbol = EXCLUDED.symbol,
+		               exchangerate = EXCLUDED.exchangerate,
+		               updated_at = NOW()`,
+		code, symbol, rate,
+	)
+	return err
+}
+
+// GetCustomerCurrencyCode returns nil when the customer uses the base currency
+func GetCustomerCurrencyCode(ctx context.Context, db *pgxpool.Pool, customerID int) (*string, error) {
+	var code *string
+	err := db.QueryRow(ctx,
+		`SELECT currencycode FROM customers WHERE customerid = $1`,
+		customerID,
+	).Scan(&code)
+	return code, err
+}
+
+func SetCustomerCurrency(ctx context.Context, db *pgxpool.Pool, customerID int, code *string) error {
+	_, err := db.Exec(ctx,
+		`UPDATE customers SET currencycode = $1 WHERE customerid = $2`,
+		code, customerID,
+	)
+	return err
+}
+
+/*
+GetLocalGamePrice – the developer's regional price for the currency if set,
+otherwise the base price converted at rate. The active discount applies to both;
+listPrice is the price before it.
+*/
+func GetLocalGamePrice(ctx context.Context, db *pgxpool.Pool, gameID int, code string, rate float64) (price, listPrice float64, err error) {
+	query := `
+        SELECT ROUND(
+                   COALESCE(gp.price, ROUND(g.price * $3, 2))
+                   * (100 - COALESCE(disc.percentoff, 0)) / 100, 2),
+               COALESCE(gp.price, ROUND(g.price * $3, 2))
+        FROM games g` + activeDiscountJoin + `
+        LEFT JOIN game_prices gp
+            ON gp.gameid = g.gameid
+           AND gp.currencycode = $2
+        WHERE g.gameid = $1
+          AND g.deleted_at IS NULL
+          AND g.hidden_at IS NULL;
+    `
+
+	err = db.QueryRow(ctx, query, gameID, code, rate).Scan(&price, &listPrice)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, 0, errors.New("game not found")
+		}
+		return 0, 0, err
+	}
+
+	return price, listPrice, nil
+}
+
+func GetRegionalPrices(ctx context.Context, db *pgxpool.Pool, gameID int) ([]RegionalPrice, error) {
+	rows, err := db.Query(ctx,
+		`SELECT gp.currencycode, c.symbol, gp.price
+		 FROM game_prices gp
+		 JOIN currencies c ON c.currencycode = gp.currencycode
+		 WHERE gp.gameid = $1
+		 ORDER BY gp.currencycode`,
+		gameID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []RegionalPrice{}
+	for rows.Next() {
+		var p RegionalPrice
+		if err := rows.Scan(&p.CurrencyCode, &p.Symbol, &p.Price); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+
+	return list, rows.Err()
+}
+
+func SetRegionalPrice(ctx context.Context, db *pgxpool.Pool, devID, gameID int, code string, price float64) error {
+	owned, err := IsGameOwnedByDeveloper(ctx, db, devID, gameID)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return errors.New("permission denied: you can only price your own games")
+	}
+
+	_, err = db.Exec(ctx,
+		`INSERT INTO game_prices (gameid, currencycode, price)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (gameid, currencycode)
+		 DO UPDATE SET price = EXCLUDED.price, updated_at = NOW()`,
+		gameID, code, price,
+	)
+	return err
+}
+
+func RemoveRegionalPrice(ctx context.Context, db *pgxpool.Pool, devID, gameID int, code string) error {
+	tag, err := db.Exec(ctx,
+		`DELETE FROM game_prices gp
+		 USING games g
+		 WHERE gp.gameid = g.gameid
+		   AND gp.gameid = $1
+		   AND gp.currencycode = $2
+		   AND g.developerid = $3`,
+		gameID, code, devID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("regional price not found")
+	}
+	return nil
+}
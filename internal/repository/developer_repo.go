@@ -16,7 +16,8 @@ type GameSalesReport struct {
 	GameID    int
 	Title     string
 	UnitsSold int
-	Revenue   float64
+	Revenue   float64 // converted to the base currency
+	Wishlists int
 }
 
 func GetDeveloperByID(ctx context.Context, db *pgxpool.Pool, developerID int) (*Developer, error) {
@@ -108,9 +109,18 @@ func GetDeveloperSalesReport(ctx context.Context, db *pgxpool.Pool, developerID
 			g.gameid,
 			g.title,
 			COALESCE(SUM(oi.quantity), 0) AS units_sold,
-			COALESCE(SUM(oi.quantity * oi.priceatpurchase), 0) AS revenue
+			COALESCE(ROUND(SUM(oi.quantity * oi.priceatpurchase / o.exchangerate), 2), 0) AS revenue,
+			(
+				SELECT COUNT(*) FROM wishlists w
+				WHERE w.gameid = g.gameid AND w.deleted_at IS NULL
+			) AS wishlists
 		FROM games g
-		LEFT JOIN orderitems oi
+		LEFT JOIN (
+			orderitems oi
+			JOIN orders o
+				ON o.orderid = oi.orderid
+				AND o.status = 'paid'
+		)
 			ON g.gameid = oi.gameid
 			AND oi.deleted_at IS NULL
 		WHERE g.developerid = $1
@@ -129,7 +139,7 @@ func GetDeveloperSalesReport(ctx context.Context, db *pgxpool.Pool, developerID
 
 	for rows.Next() {
 		var r GameSalesReport
-		if err := rows.Scan(&r.GameID, &r.Title, &r.UnitsSold, &r.Revenue); err != nil {
+		if err := rows.Scan(&r.GameID, &r.Title, &r.UnitsSold, &r.Revenue, &r.Wishlists); err != nil {
 			return nil, err
 		}
 		list = append(list, r)
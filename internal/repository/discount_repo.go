@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Discount struct {
+	DiscountID  int
+	DeveloperID int
+	GameID      *int // nil = applies to the developer's whole catalog
+	GameTitle   *string
+	PercentOff  int
+	StartsAt    time.Time
+	EndsAt      time.Time
+}
+
+// activeDiscountJoin attaches the best running discount of the game aliased
+// as "g" under the alias "disc" (disc.percentoff / disc.endsat, NULL if none).
+const activeDiscountJoin = `
+        LEFT JOIN LATERAL (
+            SELECT d.percentoff, d.endsat
+            FROM discounts d
+            WHERE d.developerid = g.developerid
+              AND (d.gameid IS NULL OR d.gameid = g.gameid)
+              AND d.startsat <= NOW()
+              AND d.endsat > NOW()
+              AND d.deleted_at IS NULL
+            ORDER BY d.percentoff DESC, d.endsat DESC
+            LIMIT 1
+        ) disc ON true
+`
+
+// effectivePriceSQL is the game price after the active discount
+const effectivePriceSQL = `ROUND(g.price * (100 - COALESCE(disc.percentoff, 0)) / 100, 2)`
+
+func CreateDiscount(ctx context.Context, db *pgxpool.Pool, devID int, gameID *int, percentOff int, startsAt, endsAt time.Time) (int, error) {
+	if gameID != nil {
+		owned, err := IsGameOwnedByDeveloper(ctx, db, devID, *gameID)
+		if err != nil {
+			return 0, err
+		}
+		if !owned {
+			return 0, errors.New("permission denied: you can only discount your own games")
+		}
+	}
+
+	var id int
+	err := db.QueryRow(ctx,
+		`INSERT INTO discounts (developerid, gameid, percentoff, startsat, endsat)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING discountid`,
+		devID, gameID, percentOff, startsAt, endsAt,
+	).Scan(&id)
+
+	return id, err
+}
+
+// GetDeveloperDiscounts returns running and upcoming discounts of a developer
+func GetDeveloperDiscounts(ctx context.Context, db *pgxpool.Pool, devID int) ([]Discount, error) {
+	query := `
+        SELECT d.discountid, d.gameid, g.title, d.percentoff, d.startsat, d.endsat
+        FROM discounts d
+        LEFT JOIN games g ON g.gameid = d.gameid
+        WHERE d.developerid = $1
+          AND d.endsat > NOW()
+          AND d.deleted_at IS NULL
+        ORDER BY d.startsat;
+    `
+
+	rows, err := db.Query(ctx, query, devID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Discount{}
+	for rows.Next() {
+		var d Discount
+		if err := rows.Scan(
+			&d.DiscountID,
+			&d.GameID,
+			&d.GameTitle,
+			&d.PercentOff,
+			&d.StartsAt,
+			&d.EndsAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+
+	return list, rows.Err()
+}
+
+func CancelDiscount(ctx context.Context, db *pgxpool.Pool, devID, discountID int) error {
+	tag, err := db.Exec(ctx,
+		`UPDATE discounts
+		 SET deleted_at = NOW()
+		 WHERE discountid = $1
+		   AND developerid = $2
+		   AND deleted_at IS NULL`,
+		discountID, devID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("discount not found")
+	}
+	return nil
+}
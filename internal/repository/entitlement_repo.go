@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Entitlement struct {
+	EntitlementID int
+	GameID        int
+	Title         string
+	OrderItemID   int
+	ReleaseDate   *time.Time
+	UnlockedAt    *time.Time
+	CreatedAt     time.Time
+}
+
+/*
+grantEntitlementsForOrder – gives the customer one entitlement per order item.
+Games that are not released yet are granted locked (pre-order).
+Gift orders grant nothing until the recipient accepts, and then go to the recipient.
+*/
+func grantEntitlementsForOrder(ctx context.Context, tx pgx.Tx, orderID int) error {
+	query := `
+        INSERT INTO entitlements (customerid, gameid, orderitemid, unlocked_at)
+        SELECT COALESCE(gf.recipientcustomerid, o.customerid),
+               oi.gameid,
+               oi.orderitemid,
+               CASE WHEN g.released_at IS NOT NULL THEN NOW() END
+        FROM orderitems oi
+        JOIN orders o ON o.orderid = oi.orderid
+        JOIN games g ON g.gameid = oi.gameid
+        LEFT JOIN gifts gf ON gf.orderid = o.orderid
+        WHERE oi.orderid = $1
+          AND oi.deleted_at IS NULL
+          AND (gf.giftid IS NULL OR gf.status = 'accepted')
+        ON CONFLICT (orderitemid) DO NOTHING;
+    `
+	_, err := tx.Exec(ctx, query, orderID)
+	return err
+}
+
+/*
+unlockEntitlementsForGame – unlocks every pre-ordered entitlement of a game
+and returns the auth IDs of the customers that were unlocked
+*/
+func unlockEntitlementsForGame(ctx context.Context, tx pgx.Tx, gameID int) ([]int, error) {
+	query := `
+        WITH unlocked AS (
+            UPDATE entitlements
+            SET unlocked_at = NOW()
+            WHERE gameid = $1
+              AND unlocked_at IS NULL
+              AND deleted_at IS NULL
+            RETURNING customerid
+        )
+        SELECT DISTINCT c.authid
+        FROM unlocked u
+        JOIN customers c ON c.customerid = u.customerid;
+    `
+
+	rows, err := tx.Query(ctx, query, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var authIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		authIDs = append(authIDs, id)
+	}
+
+	return authIDs, rows.Err()
+}
+
+/*
+GetCustomerEntitlements – the customer's library, pre-orders included
+*/
+func GetCustomerEntitlements(ctx context.Context, db *pgxpool.Pool, customerID int) ([]Entitlement, error) {
+	query := `
+        SELECT e.entitlementid, e.gameid, g.title, e.orderitemid,
+               g.releasedate, e.unlocked_at, e.created_at
+        FROM entitlements e
+        JOIN games g ON g.gameid = e.gameid
+        WHERE e.customerid = $1
+          AND e.deleted_at IS NULL
+        ORDER BY e.created_at;
+    `
+
+	rows, err := db.Query(ctx, query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Entitlement{}
+	for rows.Next() {
+		var e Entitlement
+		if err := rows.Scan(
+			&e.EntitlementID,
+			&e.GameID,
+			&e.Title,
+			&e.OrderItemID,
+			&e.ReleaseDate,
+			&e.UnlockedAt,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+
+	return list, rows.Err()
+}
+
+// OwnsGame reports whether the customer holds an entitlement (locked or not) for the game
+func OwnsGame(ctx context.Context, db *pgxpool.Pool, customerID, gameID int) (bool, error) {
+	var exists bool
+	err := db.QueryRow(ctx,
+		`SELECT EXISTS(
+			SELECT 1 FROM entitlements
+			WHERE customerid = $1 AND gameid = $2 AND deleted_at IS NULL
+		)`,
+		customerID, gameID,
+	).Scan(&exists)
+	return exists, err
+}
+
+// HasUnlockedGame reports whether the customer can play the game: a pre-order
+// that has not been released yet does not count
+func HasUnlockedGame(ctx context.Context, db *pgxpool.Pool, customerID, gameID int) (bool, error) {
+	var exists bool
+	err := db.QueryRow(ctx,
+		`SELECT EXISTS(
+			SELECT 1 FROM entitlements
+			WHERE customerid = $1 AND gameid = $2
+			  AND unlocked_at IS NOT NULL AND deleted_at IS NULL
+		)`,
+		customerID, gameID,
+	).Scan(&exists)
+	return exists, err
+}
+
+// GetOrderEntitlements returns the entitlements granted by an order
+func GetOrderEntitlements(ctx context.Context, db *pgxpool.Pool, orderID int) ([]Entitlement, error) {
+	query := `
+        SELECT e.entitlementid, e.gameid, g.title, e.orderitemid,
+               g.releasedate, e.unlocked_at, e.created_at
+        FROM entitlements e
+        JOIN orderitems oi ON oi.orderitemid = e.orderitemid
+        JOIN games g ON g.gameid = e.gameid
+        WHERE oi.orderid = $1
+          AND e.deleted_at IS NULL
+        ORDER BY e.entitlementid;
+    `
+
+	rows, err := db.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Entitlement{}
+	for rows.Next() {
+		var e Entitlement
+		if err := rows.Scan(
+			&e.EntitlementID,
+			&e.GameID,
+			&e.Title,
+			&e.OrderItemID,
+			&e.ReleaseDate,
+			&e.UnlockedAt,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+
+	return list, rows.Err()
+}
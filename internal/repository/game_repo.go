@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -10,25 +11,42 @@ import (
 )
 
 type GameList struct {
-	GameID int
-	Title  string
+	GameID          int
+	DeveloperID     int
+	Title           string
+	Price           float64 // effective price, discount applied
+	DiscountPercent int
+	ReleaseDate     *time.Time
+	Released        bool
 }
 
 type GameDetails struct {
-	GameID        int
-	Title         string
-	Price         float64
-	ReleaseDate   *time.Time
-	DeveloperName string
-	Genres        []string
+	GameID          int
+	Title           string
+	Price           float64 // effective price, discount applied
+	BasePrice       float64
+	DiscountPercent int
+	DiscountEndsAt  *time.Time
+	LowestPrice30d  *float64 // nil when there is no recorded history
+	ReleaseDate     *time.Time
+	Released        bool
+	DeveloperName   string
+	BaseGameTitle   *string // set when the game is a DLC
+	AverageRating   float64
+	RatingCount     int
+	Genres          []string
+	TopTags         []TagCount
 }
 
 func GetAllGames(ctx context.Context, db *pgxpool.Pool) ([]GameList, error) {
 	query := `
-        SELECT gameid, title
-        FROM games
-        WHERE deleted_at IS NULL
-        ORDER BY gameid;
+        SELECT g.gameid, g.title, ` + effectivePriceSQL + `,
+               COALESCE(disc.percentoff, 0),
+               g.releasedate, g.released_at IS NOT NULL
+        FROM games g` + activeDiscountJoin + `
+        WHERE g.deleted_at IS NULL
+          AND g.hidden_at IS NULL
+        ORDER BY g.gameid;
     `
 
 	rows, err := db.Query(ctx, query)
@@ -41,7 +59,7 @@ func GetAllGames(ctx context.Context, db *pgxpool.Pool) ([]GameList, error) {
 
 	for rows.Next() {
 		var g GameList
-		if err := rows.Scan(&g.GameID, &g.Title); err != nil {
+		if err := rows.Scan(&g.GameID, &g.Title, &g.Price, &g.DiscountPercent, &g.ReleaseDate, &g.Released); err != nil {
 			return nil, err
 		}
 		games = append(games, g)
@@ -50,18 +68,148 @@ func GetAllGames(ctx context.Context, db *pgxpool.Pool) ([]GameList, error) {
 	return games, nil
 }
 
+// GetComingSoonGames returns unreleased games ordered by release date
+func GetComingSoonGames(ctx context.Context, db *pgxpool.Pool) ([]GameList, error) {
+	query := `
+        SELECT g.gameid, g.title, ` + effectivePriceSQL + `,
+               COALESCE(disc.percentoff, 0),
+               g.releasedate, false
+        FROM games g` + activeDiscountJoin + `
+        WHERE g.released_at IS NULL
+          AND g.deleted_at IS NULL
+          AND g.hidden_at IS NULL
+        ORDER BY g.releasedate NULLS LAST, g.gameid;
+    `
+
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []GameList
+
+	for rows.Next() {
+		var g GameList
+		if err := rows.Scan(&g.GameID, &g.Title, &g.Price, &g.DiscountPercent, &g.ReleaseDate, &g.Released); err != nil {
+			return nil, err
+		}
+		games = append(games, g)
+	}
+
+	return games, nil
+}
+
+// releasedOnInsertSQL is the released_at of a new game with release date $3:
+// games without a date, or with one that has arrived, are out straight away
+const releasedOnInsertSQL = `CASE WHEN $3::date IS NULL OR $3::date <= CURRENT_DATE THEN NOW() END`
+
+// ErrAlreadyReleased: buyers of a released game have it unlocked, so it
+// cannot go back to pre-order
+var ErrAlreadyReleased = errors.New("a released game cannot be moved to a future release date")
+
+type ReleasedGame struct {
+	GameList
+	UnlockedAuthIDs []int // customers whose pre-orders were unlocked
+}
+
+/*
+ReleaseDueGames – releases every game whose release date has arrived (or that
+has none). Each game is marked released and its pre-orders unlocked in one
+transaction, so a failure leaves that game for the next run.
+*/
+func ReleaseDueGames(ctx context.Context, db *pgxpool.Pool) ([]ReleasedGame, error) {
+	rows, err := db.Query(ctx,
+		`SELECT gameid
+		 FROM games
+		 WHERE released_at IS NULL
+		   AND (releasedate IS NULL OR releasedate <= CURRENT_DATE)
+		   AND deleted_at IS NULL
+		 ORDER BY gameid`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var due []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		due = append(due, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var released []ReleasedGame
+	var errs []error
+	for _, id := range due {
+		g, err := releaseGame(ctx, db, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("release game %d: %w", id, err))
+			continue
+		}
+		if g != nil {
+			released = append(released, *g)
+		}
+	}
+	return released, errors.Join(errs...)
+}
+
+// releaseGame returns nil if the game was released by someone else meanwhile
+func releaseGame(ctx context.Context, db *pgxpool.Pool, gameID int) (*ReleasedGame, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var g ReleasedGame
+	err = tx.QueryRow(ctx,
+		`UPDATE games
+		 SET released_at = NOW()
+		 WHERE gameid = $1
+		   AND released_at IS NULL
+		 RETURNING gameid, developerid, title, releasedate, true`,
+		gameID,
+	).Scan(&g.GameID, &g.DeveloperID, &g.Title, &g.ReleaseDate, &g.Released)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	g.UnlockedAuthIDs, err = unlockEntitlementsForGame(ctx, tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &g, tx.Commit(ctx)
+}
+
 func GetGameDetails(ctx context.Context, db *pgxpool.Pool, gameID int) (*GameDetails, error) {
 	query := `
         SELECT 
             g.gameid,
             g.title,
+            ` + effectivePriceSQL + `,
             g.price,
+            COALESCE(disc.percentoff, 0),
+            disc.endsat,
             g.releasedate,
-            d.developername
+            g.released_at IS NOT NULL,
+            d.developername,
+            bg.title
         FROM games g
         JOIN developers d ON d.developerid = g.developerid
+        LEFT JOIN games bg ON bg.gameid = g.basegameid` + activeDiscountJoin + `
         WHERE g.gameid = $1
-          AND g.deleted_at IS NULL;
+          AND g.deleted_at IS NULL
+          AND g.hidden_at IS NULL;
     `
 
 	var gd GameDetails
@@ -70,8 +218,13 @@ func GetGameDetails(ctx context.Context, db *pgxpool.Pool, gameID int) (*GameDet
 		&gd.GameID,
 		&gd.Title,
 		&gd.Price,
+		&gd.BasePrice,
+		&gd.DiscountPercent,
+		&gd.DiscountEndsAt,
 		&gd.ReleaseDate,
+		&gd.Released,
 		&gd.DeveloperName,
+		&gd.BaseGameTitle,
 	)
 
 	if err != nil {
@@ -89,6 +242,23 @@ func GetGameDetails(ctx context.Context, db *pgxpool.Pool, gameID int) (*GameDet
 
 	gd.Genres = genres
 
+	lowest, err := GetLowestPrice30Days(ctx, db, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	gd.LowestPrice30d = lowest
+
+	gd.AverageRating, gd.RatingCount, err = GetGameRating(ctx, db, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	gd.TopTags, err = GetGameTopTags(ctx, db, gameID, 5)
+	if err != nil {
+		return nil, err
+	}
+
 	return &gd, nil
 }
 
@@ -120,12 +290,14 @@ func GetGameGenres(ctx context.Context, db *pgxpool.Pool, gameID int) ([]string,
 	return genres, nil
 }
 
+// GetGamePrice returns the effective price of a game, active discount applied
 func GetGamePrice(ctx context.Context, db *pgxpool.Pool, gameID int) (float64, error) {
 	query := `
-        SELECT price
-        FROM games
-        WHERE gameid = $1
-          AND deleted_at IS NULL;
+        SELECT ` + effectivePriceSQL + `
+        FROM games g` + activeDiscountJoin + `
+        WHERE g.gameid = $1
+          AND g.deleted_at IS NULL
+          AND g.hidden_at IS NULL;
     `
 
 	var price float64
@@ -157,24 +329,37 @@ func AddGame(ctx context.Context, db *pgxpool.Pool, title string, price float64,
 		return 0, errors.New("developer not found")
 	}
 
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
 	// Insert and return ID
 	var id int
-	err = db.QueryRow(ctx,
-		`INSERT INTO games (title, price, releasedate, developerid)
-		 VALUES ($1, $2, $3, $4)
+	err = tx.QueryRow(ctx,
+		`INSERT INTO games (title, price, releasedate, developerid, released_at)
+		 VALUES ($1, $2, $3, $4, `+releasedOnInsertSQL+`)
 		 RETURNING gameid`,
 		title, price, releaseDate, developerID,
 	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := recordPriceHistory(ctx, tx, id, "initial price"); err != nil {
+		return 0, err
+	}
 
-	return id, err
+	return id, tx.Commit(ctx)
 }
 
-func RemoveGame(ctx context.Context, db *pgxpool.Pool, gameID int, requesterRole string, requesterDevID int) error {
+func RemoveGame(ctx context.Context, tx pgx.Tx, gameID int, requesterRole string, requesterDevID int) error {
 
 	// If requester is developer → check ownership
 	if requesterRole == "developer" {
 		var ownerID int
-		err := db.QueryRow(ctx,
+		err := tx.QueryRow(ctx,
 			`SELECT developerid FROM games WHERE gameid = $1 AND deleted_at IS NULL`,
 			gameID,
 		).Scan(&ownerID)
@@ -188,7 +373,7 @@ func RemoveGame(ctx context.Context, db *pgxpool.Pool, gameID int, requesterRole
 	}
 
 	// Admin OR owner developer → proceed delete
-	_, err := db.Exec(ctx,
+	_, err := tx.Exec(ctx,
 		`UPDATE games 
 		 SET deleted_at = NOW() 
 		 WHERE gameid = $1 AND deleted_at IS NULL`,
@@ -198,15 +383,23 @@ func RemoveGame(ctx context.Context, db *pgxpool.Pool, gameID int, requesterRole
 }
 
 func UpdateGameDetails(ctx context.Context, db *pgxpool.Pool, id int, title string, price float64, releaseDate string, devID int) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
 
 	// Step 1 — check ownership
 	var ownerID int
-	err := db.QueryRow(ctx,
-		`SELECT developerid 
+	var oldPrice float64
+	var released, future bool
+	err = tx.QueryRow(ctx,
+		`SELECT developerid, price, released_at IS NOT NULL, $2::date > CURRENT_DATE
 		 FROM games 
-		 WHERE gameid=$1 AND deleted_at IS NULL`,
-		id,
-	).Scan(&ownerID)
+		 WHERE gameid=$1 AND deleted_at IS NULL
+		 FOR UPDATE`,
+		id, releaseDate,
+	).Scan(&ownerID, &oldPrice, &released, &future)
 	if err != nil {
 		return err
 	}
@@ -216,18 +409,32 @@ func UpdateGameDetails(ctx context.Context, db *pgxpool.Pool, id int, title stri
 		return errors.New("permission denied: you can only edit your own games")
 	}
 
+	// an unreleased game whose new date has arrived is released by the release job
+	if released && future {
+		return ErrAlreadyReleased
+	}
+
 	// Step 3 — update allowed fields
-	_, err = db.Exec(ctx,
+	_, err = tx.Exec(ctx,
 		`UPDATE games
 		 SET title=$1,
 		     price=$2,
 		     releasedate=$3
-		 WHERE gameid=$4 
-		   AND deleted_at IS NULL`,
+		 WHERE gameid=$4`,
 		title, price, releaseDate, id,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// Step 4 — keep a record of the price change
+	if oldPrice != price {
+		if err := recordPriceHistory(ctx, tx, id, "price change"); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
 func AddGenreToGame(ctx context.Context, db *pgxpool.Pool, gameID, genreID int) error {
@@ -277,3 +484,180 @@ func UpdateGameGenres(ctx context.Context, db *pgxpool.Pool, gameID int, genreID
 	}
 	return nil
 }
+
+type CatalogGame struct {
+	GameID      int
+	SKU         string
+	Title       string
+	Price       float64 // base price, no discount
+	ReleaseDate *time.Time
+	Genres      []string
+}
+
+// GetGameIDBySKU returns nil when the developer has no live game with that SKU
+func GetGameIDBySKU(ctx context.Context, db *pgxpool.Pool, devID int, sku string) (*int, error) {
+	var id int
+	err := db.QueryRow(ctx,
+		`SELECT gameid FROM games
+		 WHERE developerid = $1 AND sku = $2 AND deleted_at IS NULL`,
+		devID, sku,
+	).Scan(&id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &id, nil
+}
+
+/*
+UpsertGameBySKU – updates the developer's live game with that SKU, or creates it.
+A removed game keeps its SKU but no longer matches, so re-importing it lists it anew.
+Returns the game ID and whether it was created.
+*/
+func UpsertGameBySKU(ctx context.Context, tx pgx.Tx, devID int, sku, title string, price float64, releaseDate *time.Time) (int, bool, error) {
+	var id int
+	var oldPrice float64
+	var released, future bool
+	err := tx.QueryRow(ctx,
+		`SELECT gameid, price, released_at IS NOT NULL, COALESCE($3::date > CURRENT_DATE, false)
+		 FROM games
+		 WHERE developerid = $1 AND sku = $2 AND deleted_at IS NULL
+		 FOR UPDATE`,
+		devID, sku, releaseDate,
+	).Scan(&id, &oldPrice, &released, &future)
+
+	if err == pgx.ErrNoRows {
+		err := tx.QueryRow(ctx,
+			`INSERT INTO games (title, price, releasedate, developerid, sku, released_at)
+			 VALUES ($1, $2, $3, $4, $5, `+releasedOnInsertSQL+`)
+			 RETURNING gameid`,
+			title, price, releaseDate, devID, sku,
+		).Scan(&id)
+		if err != nil {
+			return 0, false, err
+		}
+
+		if err := recordPriceHistory(ctx, tx, id, "initial price"); err != nil {
+			return 0, false, err
+		}
+		return id, true, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if released && future {
+		return 0, false, ErrAlreadyReleased
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE games
+		 SET title = $1, price = $2, releasedate = $3
+		 WHERE gameid = $4`,
+		title, price, releaseDate, id,
+	)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if oldPrice != price {
+		if err := recordPriceHistory(ctx, tx, id, "price change"); err != nil {
+			return 0, false, err
+		}
+	}
+
+	return id, false, nil
+}
+
+// SetGameGenres replaces a game's genres inside tx
+func SetGameGenres(ctx context.Context, tx pgx.Tx, gameID int, genreIDs []int) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM gamegenres WHERE gameid = $1`, gameID); err != nil {
+		return err
+	}
+
+	for _, gid := range genreIDs {
+		tag, err := tx.Exec(ctx,
+			`INSERT INTO gamegenres (gameid, genreid)
+			 SELECT $1, genreid FROM genres WHERE genreid = $2 AND deleted_at IS NULL
+			 ON CONFLICT (gameid, genreid) DO NOTHING`,
+			gameID, gid,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return errors.New("genre not found")
+		}
+	}
+	return nil
+}
+
+/*
+GetDeveloperCatalog – every live game of a developer with its genres, for export.
+Every game has a SKU (assigned on insert), so the export can be re-imported.
+*/
+func GetDeveloperCatalog(ctx context.Context, db *pgxpool.Pool, devID int) ([]CatalogGame, error) {
+	rows, err := db.Query(ctx,
+		`SELECT gameid, COALESCE(sku, 'GAME-' || gameid), title, price, releasedate
+		 FROM games
+		 WHERE developerid = $1 AND deleted_at IS NULL
+		 ORDER BY gameid`,
+		devID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []CatalogGame
+	for rows.Next() {
+		var g CatalogGame
+		if err := rows.Scan(&g.GameID, &g.SKU, &g.Title, &g.Price, &g.ReleaseDate); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range list {
+		genres, err := GetGameGenres(ctx, db, list[i].GameID)
+		if err != nil {
+			return nil, err
+		}
+		list[i].Genres = genres
+	}
+
+	return list, nil
+}
+
+// SetGameMaxPerOrder sets how many copies of a game one order may contain
+func SetGameMaxPerOrder(ctx context.Context, db *pgxpool.Pool, devID, gameID, limit int) error {
+	tag, err := db.Exec(ctx,
+		`UPDATE games SET maxperorder = $1
+		 WHERE gameid = $2 AND developerid = $3 AND deleted_at IS NULL`,
+		limit, gameID, devID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("game not found or not owned by you")
+	}
+	return nil
+}
+
+func GetGameMaxPerOrder(ctx context.Context, db *pgxpool.Pool, gameID int) (int, error) {
+	var limit int
+	err := db.QueryRow(ctx,
+		`SELECT maxperorder FROM games WHERE gameid = $1 AND deleted_at IS NULL`,
+		gameID,
+	).Scan(&limit)
+	if err == pgx.ErrNoRows {
+		return 0, errors.New("game not found")
+	}
+	return limit, err
+}
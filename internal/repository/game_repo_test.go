@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestUpsertGameBySKUAfterRemoval(t *testing.T) {
+	db := testPool(t)
+	ctx := context.Background()
+
+	gameID := createTestGame(t, db)
+	var devID int
+	var sku string
+	err := db.QueryRow(ctx,
+		`SELECT developerid, sku FROM games WHERE gameid = $1`, gameID,
+	).Scan(&devID, &sku)
+	if err != nil {
+		t.Fatalf("read game: %v", err)
+	}
+	if sku != "GAME-"+strconv.Itoa(gameID) {
+		t.Fatalf("sku = %q, want one assigned on insert", sku)
+	}
+
+	if _, err := db.Exec(ctx, `UPDATE games SET deleted_at = NOW() WHERE gameid = $1`, gameID); err != nil {
+		t.Fatalf("remove game: %v", err)
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+
+	newID, created, err := UpsertGameBySKU(ctx, tx, devID, sku, "Relisted", 5, nil)
+	if err != nil {
+		t.Fatalf("UpsertGameBySKU: %v", err)
+	}
+	if !created || newID == gameID {
+		t.Fatalf("got game %d (created %v), want a new listing", newID, created)
+	}
+
+	sameID, created, err := UpsertGameBySKU(ctx, tx, devID, sku, "Relisted again", 6, nil)
+	if err != nil {
+		t.Fatalf("second UpsertGameBySKU: %v", err)
+	}
+	if created || sameID != newID {
+		t.Errorf("got game %d (created %v), want update of %d", sameID, created, newID)
+	}
+}
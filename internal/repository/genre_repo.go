@@ -2,23 +2,51 @@ package repository
 
 import (
 	"context"
+	"errors"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type GenreList struct {
 	GenreID   int
 	GenreName string
+	ParentID  *int
 }
 
+// genreTreeCTE expands genre $1 into itself plus every live descendant
+const genreTreeCTE = `
+        WITH RECURSIVE tree AS (
+            SELECT genreid FROM genres WHERE genreid = $1 AND deleted_at IS NULL
+            UNION
+            SELECT ge.genreid
+            FROM genres ge
+            JOIN tree t ON ge.parentid = t.genreid
+            WHERE ge.deleted_at IS NULL
+        )
+`
+
 func GetAllGenre(ctx context.Context, db *pgxpool.Pool) ([]GenreList, error) {
 	query := `
-        SELECT genreid, genrename
+        SELECT genreid, genrename, parentid
         FROM genres
         WHERE deleted_at IS NULL
         ORDER BY genreid;
     `
+	return queryGenres(ctx, db, query)
+}
 
+func GetDeletedGenres(ctx context.Context, db *pgxpool.Pool) ([]GenreList, error) {
+	query := `
+        SELECT genreid, genrename, parentid
+        FROM genres
+        WHERE deleted_at IS NOT NULL
+        ORDER BY genreid;
+    `
+	return queryGenres(ctx, db, query)
+}
+
+func queryGenres(ctx context.Context, db *pgxpool.Pool, query string) ([]GenreList, error) {
 	rows, err := db.Query(ctx, query)
 	if err != nil {
 		return nil, err
@@ -29,7 +57,7 @@ func GetAllGenre(ctx context.Context, db *pgxpool.Pool) ([]GenreList, error) {
 
 	for rows.Next() {
 		var g GenreList
-		if err := rows.Scan(&g.GenreID, &g.GenreName); err != nil {
+		if err := rows.Scan(&g.GenreID, &g.GenreName, &g.ParentID); err != nil {
 			return nil, err
 		}
 		genre = append(genre, g)
@@ -38,23 +66,226 @@ func GetAllGenre(ctx context.Context, db *pgxpool.Pool) ([]GenreList, error) {
 	return genre, nil
 }
 
-func AddGenre(ctx context.Context, db *pgxpool.Pool, name string) error {
+func AddGenre(ctx context.Context, db *pgxpool.Pool, name string, parentID *int) error {
+	if parentID != nil {
+		if err := checkGenreExists(ctx, db, *parentID); err != nil {
+			return err
+		}
+	}
+
 	query := `
-        INSERT INTO genres (genrename)
-        VALUES ($1);
+        INSERT INTO genres (genrename, parentid)
+        VALUES ($1, $2);
     `
 
-	_, err := db.Exec(ctx, query, name)
+	_, err := db.Exec(ctx, query, name, parentID)
 	return err
 }
 
-func RemoveGenre(ctx context.Context, db *pgxpool.Pool, genreID int) error {
+func RemoveGenre(ctx context.Context, tx pgx.Tx, genreID int) error {
 	query := `
         UPDATE genres
         SET deleted_at = NOW()
         WHERE genreid = $1 AND deleted_at IS NULL;
     `
 
-	_, err := db.Exec(ctx, query, genreID)
+	_, err := tx.Exec(ctx, query, genreID)
+	return err
+}
+
+func RestoreGenre(ctx context.Context, db *pgxpool.Pool, genreID int) error {
+	tag, err := db.Exec(ctx,
+		`UPDATE genres
+		 SET deleted_at = NULL
+		 WHERE genreid = $1 AND deleted_at IS NOT NULL`,
+		genreID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("deleted genre not found")
+	}
+	return nil
+}
+
+func RenameGenre(ctx context.Context, db *pgxpool.Pool, genreID int, name string) error {
+	tag, err := db.Exec(ctx,
+		`UPDATE genres
+		 SET genrename = $1
+		 WHERE genreid = $2 AND deleted_at IS NULL`,
+		name, genreID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("genre not found")
+	}
+	return nil
+}
+
+/*
+SetGenreParent – makes the genre a sub-genre of parentID, or top-level when nil.
+Refuses moves that would create a cycle.
+*/
+func SetGenreParent(ctx context.Context, db *pgxpool.Pool, genreID int, parentID *int) error {
+	if err := checkGenreExists(ctx, db, genreID); err != nil {
+		return err
+	}
+
+	if parentID != nil {
+		if err := checkGenreExists(ctx, db, *parentID); err != nil {
+			return err
+		}
+
+		var cycle bool
+		err := db.QueryRow(ctx, genreTreeCTE+`
+            SELECT EXISTS(SELECT 1 FROM tree WHERE genreid = $2);
+        `, genreID, *parentID).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return errors.New("a genre cannot be placed under itself or its own sub-genre")
+		}
+	}
+
+	_, err := db.Exec(ctx,
+		`UPDATE genres SET parentid = $1 WHERE genreid = $2`,
+		parentID, genreID,
+	)
 	return err
 }
+
+/*
+MergeGenres – re-points every game and sub-genre of fromID to intoID, then
+soft-deletes fromID
+*/
+func MergeGenres(ctx context.Context, tx pgx.Tx, fromID, intoID int) error {
+	if fromID == intoID {
+		return errors.New("cannot merge a genre into itself")
+	}
+	if err := checkGenreExists(ctx, tx, fromID); err != nil {
+		return err
+	}
+	if err := checkGenreExists(ctx, tx, intoID); err != nil {
+		return err
+	}
+
+	// the target may sit anywhere below the merged genre; it takes the merged
+	// genre's place first, or handing it the merged genre's children would
+	// put it under its own sub-genre
+	var below bool
+	err := tx.QueryRow(ctx, `
+        WITH RECURSIVE ancestors AS (
+            SELECT parentid FROM genres WHERE genreid = $2
+            UNION
+            SELECT ge.parentid
+            FROM genres ge
+            JOIN ancestors a ON ge.genreid = a.parentid
+        )
+        SELECT EXISTS(SELECT 1 FROM ancestors WHERE parentid = $1);
+    `, fromID, intoID).Scan(&below)
+	if err != nil {
+		return err
+	}
+	if below {
+		_, err := tx.Exec(ctx,
+			`UPDATE genres
+			 SET parentid = (SELECT parentid FROM genres WHERE genreid = $1)
+			 WHERE genreid = $2`,
+			fromID, intoID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	steps := []string{
+		`INSERT INTO gamegenres (gameid, genreid)
+		 SELECT gameid, $2 FROM gamegenres WHERE genreid = $1
+		 ON CONFLICT (gameid, genreid) DO NOTHING`,
+		`DELETE FROM gamegenres WHERE genreid = $1`,
+		`UPDATE genres SET parentid = $2 WHERE parentid = $1`,
+		`UPDATE genres SET deleted_at = NOW() WHERE genreid = $1`,
+	}
+
+	for _, q := range steps {
+		if _, err := tx.Exec(ctx, q, fromID, intoID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GenreUsageCount returns how many live games are tagged with the genre
+func GenreUsageCount(ctx context.Context, db *pgxpool.Pool, genreID int) (int, error) {
+	var count int
+	err := db.QueryRow(ctx,
+		`SELECT COUNT(*)
+		 FROM gamegenres gg
+		 JOIN games g ON g.gameid = gg.gameid
+		 WHERE gg.genreid = $1 AND g.deleted_at IS NULL`,
+		genreID,
+	).Scan(&count)
+	return count, err
+}
+
+// GetGamesByGenre lists games in the genre or any of its sub-genres
+func GetGamesByGenre(ctx context.Context, db *pgxpool.Pool, genreID int) ([]GameList, error) {
+	query := genreTreeCTE + `
+        SELECT g.gameid, g.title, ` + effectivePriceSQL + `,
+               COALESCE(disc.percentoff, 0),
+               g.releasedate, g.released_at IS NOT NULL
+        FROM games g` + activeDiscountJoin + `
+        WHERE g.deleted_at IS NULL
+          AND g.hidden_at IS NULL
+          AND EXISTS (
+              SELECT 1 FROM gamegenres gg
+              JOIN tree t ON t.genreid = gg.genreid
+              WHERE gg.gameid = g.gameid
+          )
+        ORDER BY g.gameid;
+    `
+
+	rows, err := db.Query(ctx, query, genreID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []GameList
+	for rows.Next() {
+		var g GameList
+		if err := rows.Scan(&g.GameID, &g.Title, &g.Price, &g.DiscountPercent, &g.ReleaseDate, &g.Released); err != nil {
+			return nil, err
+		}
+		games = append(games, g)
+	}
+
+	return games, rows.Err()
+}
+
+// queryRower is satisfied by both the pool and a transaction
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func checkGenreExists(ctx context.Context, db queryRower, genreID int) error {
+	var exists bool
+	err := db.QueryRow(ctx,
+		`SELECT EXISTS(
+			SELECT 1 FROM genres
+			WHERE genreid = $1 AND deleted_at IS NULL
+		)`, genreID,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("genre not found")
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMergeGenresIntoDescendant(t *testing.T) {
+	db := testPool(t)
+	ctx := context.Background()
+
+	newGenre := func(parentID *int) int {
+		var id int
+		err := db.QueryRow(ctx,
+			`INSERT INTO genres (genrename, parentid) VALUES ($1, $2) RETURNING genreid`,
+			uniqueName("genre"), parentID,
+		).Scan(&id)
+		if err != nil {
+			t.Fatalf("insert genre: %v", err)
+		}
+		return id
+	}
+
+	tests := []struct {
+		name string
+		// builds the tree and returns the genre to merge and its target
+		build func() (from, into int)
+	}{
+		{"direct child", func() (int, int) {
+			a := newGenre(nil)
+			return a, newGenre(&a)
+		}},
+		{"grandchild", func() (int, int) {
+			a := newGenre(nil)
+			x := newGenre(&a)
+			return a, newGenre(&x)
+		}},
+		{"sibling", func() (int, int) {
+			root := newGenre(nil)
+			a := newGenre(&root)
+			newGenre(&a)
+			return a, newGenre(&root)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, into := tt.build()
+			tx, err := db.Begin(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := MergeGenres(ctx, tx, from, into); err != nil {
+				tx.Rollback(ctx)
+				t.Fatalf("MergeGenres: %v", err)
+			}
+			if err := tx.Commit(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			// walking up from the target must end at a root, never come back
+			var cyclic bool
+			err = db.QueryRow(ctx, `
+                WITH RECURSIVE up AS (
+                    SELECT parentid, 1 AS depth FROM genres WHERE genreid = $1
+                    UNION ALL
+                    SELECT ge.parentid, up.depth + 1
+                    FROM genres ge JOIN up ON ge.genreid = up.parentid
+                    WHERE up.depth < 50
+                )
+                SELECT EXISTS(SELECT 1 FROM up WHERE parentid = $1);
+            `, into).Scan(&cyclic)
+			if err != nil {
+				t.Fatalf("walk ancestors: %v", err)
+			}
+			if cyclic {
+				t.Fatal("merge left the target inside a cycle")
+			}
+
+			var orphans int
+			err = db.QueryRow(ctx,
+				`SELECT COUNT(*) FROM genres WHERE parentid = $1 AND deleted_at IS NULL`,
+				from,
+			).Scan(&orphans)
+			if err != nil {
+				t.Fatalf("count children: %v", err)
+			}
+			if orphans != 0 {
+				t.Errorf("%d genres still under the merged genre", orphans)
+			}
+		})
+	}
+}
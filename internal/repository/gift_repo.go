@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Gift struct {
+	GiftID          int
+	OrderID         int
+	SenderID        int
+	SenderName      string
+	SenderAuthID    int
+	RecipientID     int
+	RecipientName   string
+	RecipientAuthID int
+	Message         *string
+	Status          string // pending, accepted or declined
+	OrderStatus     string
+	Titles          string
+	CreatedAt       time.Time
+	RespondedAt     *time.Time
+}
+
+const giftSelect = `
+        SELECT gf.giftid, gf.orderid,
+               gf.sendercustomerid, COALESCE(s.username, ''), s.authid,
+               gf.recipientcustomerid, COALESCE(r.username, ''), r.authid,
+               gf.message, gf.status, o.status,
+               COALESCE((
+                   SELECT string_agg(g.title, ', ' ORDER BY oi.orderitemid)
+                   FROM orderitems oi
+                   JOIN games g ON g.gameid = oi.gameid
+                   WHERE oi.orderid = gf.orderid AND oi.deleted_at IS NULL
+               ), ''),
+               gf.created_at, gf.responded_at
+        FROM gifts gf
+        JOIN orders o ON o.orderid = gf.orderid
+        JOIN customers s ON s.customerid = gf.sendercustomerid
+        JOIN customers r ON r.customerid = gf.recipientcustomerid
+`
+
+func scanGifts(rows pgx.Rows) ([]Gift, error) {
+	defer rows.Close()
+
+	list := []Gift{}
+	for rows.Next() {
+		var g Gift
+		if err := rows.Scan(&g.GiftID, &g.OrderID,
+			&g.SenderID, &g.SenderName, &g.SenderAuthID,
+			&g.RecipientID, &g.RecipientName, &g.RecipientAuthID,
+			&g.Message, &g.Status, &g.OrderStatus, &g.Titles,
+			&g.CreatedAt, &g.RespondedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, g)
+	}
+	return list, rows.Err()
+}
+
+// GetGiftByOrderID returns nil when the order is not a gift
+func GetGiftByOrderID(ctx context.Context, db *pgxpool.Pool, orderID int) (*Gift, error) {
+	rows, err := db.Query(ctx, giftSelect+` WHERE gf.orderid = $1`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := scanGifts(rows)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// GetReceivedGifts lists gifts sent to the customer; unpaid gift orders are not shown
+func GetReceivedGifts(ctx context.Context, db *pgxpool.Pool, customerID int) ([]Gift, error) {
+	rows, err := db.Query(ctx, giftSelect+`
+        WHERE gf.recipientcustomerid = $1
+          AND o.status IN ('paid', 'refunded')
+        ORDER BY gf.created_at DESC`,
+		customerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanGifts(rows)
+}
+
+/*
+RespondToGift – the recipient accepts or declines a pending, paid gift.
+Accepting grants the games to the recipient; declining refunds the sender's
+payment and order. The answer and its effect commit together.
+*/
+func RespondToGift(ctx context.Context, db *pgxpool.Pool, recipientID, giftID int, accept bool) (*Gift, error) {
+	status := "declined"
+	if accept {
+		status = "accepted"
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var orderID int
+	err = tx.QueryRow(ctx,
+		`UPDATE gifts gf
+		 SET status = $1, responded_at = NOW()
+		 FROM orders o
+		 WHERE o.orderid = gf.orderid
+		   AND gf.giftid = $2
+		   AND gf.recipientcustomerid = $3
+		   AND gf.status = 'pending'
+		   AND o.status = 'paid'
+		 RETURNING gf.orderid`,
+		status, giftID, recipientID,
+	).Scan(&orderID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New("gift not found or already answered")
+		}
+		return nil, err
+	}
+
+	if accept {
+		err = grantEntitlementsForOrder(ctx, tx, orderID)
+	} else {
+		err = refundOrder(ctx, tx, orderID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return GetGiftByOrderID(ctx, db, orderID)
+}
+
+// refundOrder refunds the paid payment of a paid order and marks the order refunded
+func refundOrder(ctx context.Context, tx pgx.Tx, orderID int) error {
+	tag, err := tx.Exec(ctx,
+		`UPDATE orders SET status = 'refunded'
+		 WHERE orderid = $1 AND status = 'paid'`,
+		orderID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("only paid orders can be refunded")
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT paymentid FROM payments
+		 WHERE orderid = $1 AND paymentstatus = 'Paid'`,
+		orderID,
+	)
+	if err != nil {
+		return err
+	}
+	var paymentIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		paymentIDs = append(paymentIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range paymentIDs {
+		if err := setPaymentStatus(ctx, tx, id, "Refunded"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
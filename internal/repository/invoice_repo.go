@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Invoice struct {
+	InvoiceID     int
+	InvoiceNumber string
+	OrderID       int
+	CustomerID    int
+	Body          string
+	IssuedAt      time.Time
+}
+
+// GetInvoiceByOrderID returns nil when the order has no invoice yet
+func GetInvoiceByOrderID(ctx context.Context, db *pgxpool.Pool, orderID int) (*Invoice, error) {
+	var inv Invoice
+	err := db.QueryRow(ctx,
+		`SELECT invoiceid, invoicenumber, orderid, customerid, body, issued_at
+		 FROM invoices
+		 WHERE orderid = $1`,
+		orderID,
+	).Scan(&inv.InvoiceID, &inv.InvoiceNumber, &inv.OrderID, &inv.CustomerID, &inv.Body, &inv.IssuedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &inv, nil
+}
+
+/*
+CreateInvoice – assigns the next gap-free invoice number and stores the body
+produced by render. The table is locked so concurrent checkouts can't share a
+number; if the order was invoiced meanwhile the existing invoice is returned.
+*/
+func CreateInvoice(ctx context.Context, db *pgxpool.Pool, orderID, customerID int,
+	render func(number string, issuedAt time.Time) string) (*Invoice, error) {
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `LOCK TABLE invoices IN EXCLUSIVE MODE`); err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	if err := tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM invoices WHERE orderid = $1)`, orderID,
+	).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists {
+		tx.Rollback(ctx)
+		return GetInvoiceByOrderID(ctx, db, orderID)
+	}
+
+	var seq int
+	var now time.Time
+	if err := tx.QueryRow(ctx,
+		`SELECT COALESCE(MAX(invoiceseq), 0) + 1, LOCALTIMESTAMP(0) FROM invoices`,
+	).Scan(&seq, &now); err != nil {
+		return nil, err
+	}
+
+	inv := Invoice{
+		InvoiceNumber: fmt.Sprintf("INV-%06d", seq),
+		OrderID:       orderID,
+		CustomerID:    customerID,
+		IssuedAt:      now,
+	}
+	inv.Body = render(inv.InvoiceNumber, now)
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO invoices (invoiceseq, invoicenumber, orderid, customerid, body, issued_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING invoiceid`,
+		seq, inv.InvoiceNumber, orderID, customerID, inv.Body, now,
+	).Scan(&inv.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inv, tx.Commit(ctx)
+}
+
+// GetPaidPayment returns the successful payment of an order and its method name, or nil if unpaid
+func GetPaidPayment(ctx context.Context, db *pgxpool.Pool, orderID int) (*Payment, string, error) {
+	var p Payment
+	var method string
+	err := db.QueryRow(ctx,
+		`SELECT p.paymentid, p.orderid, p.paymentmethodid, p.amountpaid,
+		        p.paymentstatus, p.createdat, p.paidat, m.name
+		 FROM payments p
+		 JOIN paymentmethods m ON m.paymentmethodid = p.paymentmethodid
+		 WHERE p.orderid = $1
+		   AND p.paymentstatus = 'Paid'
+		 ORDER BY p.paidat DESC
+		 LIMIT 1`,
+		orderID,
+	).Scan(&p.PaymentID, &p.OrderID, &p.PaymentMethodID, &p.AmountPaid,
+		&p.PaymentStatus, &p.CreatedAt, &p.PaidAt, &method)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	return &p, method, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LoginAttempt struct {
+	AttemptID int64
+	Email     string
+	Source    string
+	Success   bool
+	Reason    *string // why a failed attempt failed
+	CreatedAt time.Time
+}
+
+// FailureStreak is the run of failures since the last success (or since a cutoff)
+type FailureStreak struct {
+	Count int
+	Last  *time.Time
+}
+
+func RecordLoginAttempt(ctx context.Context, db *pgxpool.Pool, email string, authID *int, source string, success bool, reason *string) error {
+	_, err := db.Exec(ctx,
+		`INSERT INTO login_attempts (email, authid, source, success, reason)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		email, authID, source, success, reason,
+	)
+	return err
+}
+
+/*
+EmailFailureStreak – failed attempts for an email after its last successful
+login and after since
+*/
+func EmailFailureStreak(ctx context.Context, db *pgxpool.Pool, email string, since time.Time) (FailureStreak, error) {
+	var s FailureStreak
+	err := db.QueryRow(ctx,
+		`SELECT COUNT(*), MAX(created_at)
+		 FROM login_attempts
+		 WHERE lower(email) = lower($1)
+		   AND NOT success
+		   AND created_at > $2
+		   AND created_at > COALESCE((
+		       SELECT MAX(created_at) FROM login_attempts
+		       WHERE lower(email) = lower($1) AND success
+		   ), '-infinity')`,
+		email, since,
+	).Scan(&s.Count, &s.Last)
+	return s, err
+}
+
+// SourceFailureStreak is EmailFailureStreak for everything tried from one source
+func SourceFailureStreak(ctx context.Context, db *pgxpool.Pool, source string, since time.Time) (FailureStreak, error) {
+	var s FailureStreak
+	err := db.QueryRow(ctx,
+		`SELECT COUNT(*), MAX(created_at)
+		 FROM login_attempts
+		 WHERE source = $1
+		   AND NOT success
+		   AND created_at > $2
+		   AND created_at > COALESCE((
+		       SELECT MAX(created_at) FROM login_attempts
+		       WHERE source = $1 AND success
+		   ), '-infinity')`,
+		source, since,
+	).Scan(&s.Count, &s.Last)
+	return s, err
+}
+
+// LockAccount returns false if the account was already locked
+func LockAccount(ctx context.Context, db *pgxpool.Pool, authID int, until time.Time) (bool, error) {
+	tag, err := db.Exec(ctx,
+		`UPDATE userauth SET locked_until = $2
+		 WHERE authid = $1 AND (locked_until IS NULL OR locked_until <= NOW())`,
+		authID, until,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// UnlockAccount ends the lock now rather than clearing it, so the failures
+// that caused it no longer count towards backoff or the next lock
+func UnlockAccount(ctx context.Context, tx pgx.Tx, authID int) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE userauth SET locked_until = NOW() WHERE authid = $1`,
+		authID,
+	)
+	return err
+}
+
+func GetLoginAttempts(ctx context.Context, db *pgxpool.Pool, authID, limit int) ([]LoginAttempt, error) {
+	rows, err := db.Query(ctx,
+		`SELECT la.attemptid, la.email, la.source, la.success, la.reason, la.created_at
+		 FROM login_attempts la
+		 JOIN userauth ua ON ua.authid = $1
+		 WHERE la.authid = $1 OR lower(la.email) = lower(ua.email)
+		 ORDER BY la.created_at DESC
+		 LIMIT $2`,
+		authID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []LoginAttempt{}
+	for rows.Next() {
+		var a LoginAttempt
+		if err := rows.Scan(&a.AttemptID, &a.Email, &a.Source, &a.Success, &a.Reason, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
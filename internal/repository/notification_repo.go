@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Notification struct {
+	NotificationID int
+	Message        string
+	CreatedAt      time.Time
+	ReadAt         *time.Time
+}
+
+func CreateNotification(ctx context.Context, db *pgxpool.Pool, authID int, message string) error {
+	query := `
+        INSERT INTO notifications (authid, message)
+        VALUES ($1, $2);
+    `
+	_, err := db.Exec(ctx, query, authID, message)
+	return err
+}
+
+func GetNotifications(ctx context.Context, db *pgxpool.Pool, authID int) ([]Notification, error) {
+	query := `
+        SELECT notificationid, message, created_at, read_at
+        FROM notifications
+        WHERE authid = $1
+        ORDER BY created_at DESC;
+    `
+
+	rows, err := db.Query(ctx, query, authID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Notification{}
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.NotificationID, &n.Message, &n.CreatedAt, &n.ReadAt); err != nil {
+			return nil, err
+		}
+		list = append(list, n)
+	}
+
+	return list, rows.Err()
+}
+
+func CountUnreadNotifications(ctx context.Context, db *pgxpool.Pool, authID int) (int, error) {
+	var count int
+	err := db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM notifications
+		 WHERE authid = $1 AND read_at IS NULL`,
+		authID,
+	).Scan(&count)
+	return count, err
+}
+
+func MarkNotificationsRead(ctx context.Context, db *pgxpool.Pool, authID int) error {
+	_, err := db.Exec(ctx,
+		`UPDATE notifications
+		 SET read_at = NOW()
+		 WHERE authid = $1 AND read_at IS NULL`,
+		authID,
+	)
+	return err
+}
@@ -2,17 +2,25 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type OrderHistoryItem struct {
-	OrderID       int
-	TotalPrice    float64
-	OrderDate     time.Time
-	PaymentStatus string
-	PaidAt        *time.Time
+	OrderID        int
+	TotalPrice     float64
+	CurrencySymbol string // empty for orders in the base currency
+	TaxTotal       float64
+	TaxInclusive   bool
+	OrderDate      time.Time
+	Status         string // pending, paid, cancelled or refunded
+	PaymentStatus  string
+	PaidAt         *time.Time
+	GiftRecipient  *string // set for orders bought as a gift
+	GiftStatus     *string
 }
 
 func GetOrderHistory(ctx context.Context, db *pgxpool.Pool, customerID int) ([]OrderHistoryItem, error) {
@@ -20,13 +28,28 @@ func GetOrderHistory(ctx context.Context, db *pgxpool.Pool, customerID int) ([]O
         SELECT 
             o.orderid,
             o.totalprice,
+            COALESCE(c.symbol, ''),
+            o.taxtotal,
+            o.taxinclusive,
             o.orderdate,
+            o.status,
             COALESCE(p.paymentstatus, 'Unpaid') AS paymentstatus,
-            p.paidat
+            p.paidat,
+            rc.username,
+            gf.status
         FROM orders o
-        LEFT JOIN payments p ON p.orderid = o.orderid
+        LEFT JOIN LATERAL (
+            SELECT paymentstatus, paidat
+            FROM payments
+            WHERE orderid = o.orderid
+            ORDER BY createdat DESC, paymentid DESC
+            LIMIT 1
+        ) p ON true
+        LEFT JOIN currencies c ON c.currencycode = o.currencycode
+        LEFT JOIN gifts gf ON gf.orderid = o.orderid
+        LEFT JOIN customers rc ON rc.customerid = gf.recipientcustomerid
         WHERE o.customerid = $1
-          AND o.totalprice > 0        -- completed orders only
+          AND o.status <> 'cart'        -- checked-out orders only
           AND o.deleted_at IS NULL
         ORDER BY o.orderdate ASC;
     `
@@ -46,9 +69,15 @@ func GetOrderHistory(ctx context.Context, db *pgxpool.Pool, customerID int) ([]O
 		if err := rows.Scan(
 			&item.OrderID,
 			&item.TotalPrice,
+			&item.CurrencySymbol,
+			&item.TaxTotal,
+			&item.TaxInclusive,
 			&item.OrderDate,
+			&item.Status,
 			&item.PaymentStatus,
 			&paidAt,
+			&item.GiftRecipient,
+			&item.GiftStatus,
 		); err != nil {
 			return nil, err
 		}
@@ -59,3 +88,156 @@ func GetOrderHistory(ctx context.Context, db *pgxpool.Pool, customerID int) ([]O
 
 	return result, nil
 }
+
+type OrderSummary struct {
+	OrderID      int
+	CustomerID   int
+	OrderDate    time.Time
+	Status       string
+	CheckedOutAt *time.Time
+	TotalPrice   float64
+	CurrencyCode *string // nil for the base currency
+	Symbol       *string
+	TaxRate      float64
+	TaxInclusive bool
+	TaxTotal     float64
+	Username     string
+	FullName     *string
+	Email        string
+	Address      *string
+}
+
+type OrderLine struct {
+	OrderItemID   int
+	GameID        int
+	Title         string
+	DeveloperName string
+	Quantity      int
+	UnitPrice     float64
+	ListPrice     *float64 // price before discounts, nil for older orders
+	TaxAmount     float64
+	BundleTitle   *string
+}
+
+/*
+GetOrderSummary – checked-out order with the buyer's details
+*/
+func GetOrderSummary(ctx context.Context, db *pgxpool.Pool, orderID int) (*OrderSummary, error) {
+	query := `
+        SELECT o.orderid, o.customerid, o.orderdate, o.status, o.checkedout_at, o.totalprice,
+               o.currencycode, cur.symbol,
+               o.taxrate, o.taxinclusive, o.taxtotal,
+               COALESCE(c.username, ''), c.fullname, c.email, c.address
+        FROM orders o
+        JOIN customers c ON c.customerid = o.customerid
+        LEFT JOIN currencies cur ON cur.currencycode = o.currencycode
+        WHERE o.orderid = $1
+          AND o.status <> 'cart'
+          AND o.deleted_at IS NULL;
+    `
+
+	var s OrderSummary
+	err := db.QueryRow(ctx, query, orderID).Scan(
+		&s.OrderID, &s.CustomerID, &s.OrderDate, &s.Status, &s.CheckedOutAt, &s.TotalPrice,
+		&s.CurrencyCode, &s.Symbol,
+		&s.TaxRate, &s.TaxInclusive, &s.TaxTotal,
+		&s.Username, &s.FullName, &s.Email, &s.Address,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New("order not found")
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func GetOrderLines(ctx context.Context, db *pgxpool.Pool, orderID int) ([]OrderLine, error) {
+	query := `
+        SELECT oi.orderitemid, oi.gameid, g.title, d.developername,
+               oi.quantity, oi.priceatpurchase, oi.listprice, oi.taxamount, b.title
+        FROM orderitems oi
+        JOIN games g ON g.gameid = oi.gameid
+        JOIN developers d ON d.developerid = g.developerid
+        LEFT JOIN bundles b ON b.bundleid = oi.bundleid
+        WHERE oi.orderid = $1
+          AND oi.deleted_at IS NULL
+        ORDER BY oi.orderitemid;
+    `
+
+	rows, err := db.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []OrderLine{}
+	for rows.Next() {
+		var l OrderLine
+		if err := rows.Scan(&l.OrderItemID, &l.GameID, &l.Title, &l.DeveloperName,
+			&l.Quantity, &l.UnitPrice, &l.ListPrice, &l.TaxAmount, &l.BundleTitle); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+
+	return lines, rows.Err()
+}
+
+/*
+CancelOrder – customers can only cancel their own orders that are still unpaid
+*/
+func CancelOrder(ctx context.Context, db *pgxpool.Pool, customerID, orderID int) error {
+	tag, err := db.Exec(ctx,
+		`UPDATE orders SET status = 'cancelled', cancelled_at = NOW()
+		 WHERE orderid = $1
+		   AND customerid = $2
+		   AND status = 'pending'`,
+		orderID, customerID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("only unpaid orders can be cancelled")
+	}
+	return nil
+}
+
+type ExpiredOrder struct {
+	OrderID int
+	AuthID  int
+}
+
+/*
+CancelExpiredOrders – cancels pending orders checked out more than timeout ago
+and returns them so their customers can be told
+*/
+func CancelExpiredOrders(ctx context.Context, db *pgxpool.Pool, timeout time.Duration) ([]ExpiredOrder, error) {
+	query := `
+        UPDATE orders o
+        SET status = 'cancelled', cancelled_at = NOW()
+        FROM customers c
+        WHERE c.customerid = o.customerid
+          AND o.status = 'pending'
+          AND o.checkedout_at < NOW() - make_interval(secs => $1)
+        RETURNING o.orderid, c.authid;
+    `
+
+	rows, err := db.Query(ctx, query, timeout.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []ExpiredOrder{}
+	for rows.Next() {
+		var e ExpiredOrder
+		if err := rows.Scan(&e.OrderID, &e.AuthID); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+
+	return list, rows.Err()
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -33,7 +36,11 @@ type PaymentLog struct {
 type AdminTransaction struct {
 	OrderID       int
 	CustomerID    int
-	TotalPrice    float64
+	TotalPrice    float64 // in the order's currency
+	CurrencyCode  string
+	BaseTotal     float64 // converted to the base currency at the checkout rate
+	TaxTotal      float64
+	BaseTaxTotal  float64
 	OrderDate     time.Time
 	PaymentStatus string
 	PaidAt        *time.Time
@@ -137,37 +144,38 @@ func GetPaymentsByOrderID(ctx context.Context, db *pgxpool.Pool, orderID int) ([
 
 // UpdatePaymentStatus updates status and optionally sets PaidAt when status = 'Paid'
 func UpdatePaymentStatus(ctx context.Context, db *pgxpool.Pool, paymentID int, newStatus string) error {
-	// Fetch current payment to know old status
-	cur, err := GetPaymentByID(ctx, db, paymentID)
+	tx, err := db.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
 
-	tx, err := db.Begin(ctx)
+	if err := setPaymentStatus(ctx, tx, paymentID, newStatus); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// setPaymentStatus changes the payment's status inside tx and logs the change
+func setPaymentStatus(ctx context.Context, tx pgx.Tx, paymentID int, newStatus string) error {
+	var oldStatus string
+	err := tx.QueryRow(ctx,
+		`SELECT paymentstatus FROM payments WHERE paymentid = $1 FOR UPDATE`,
+		paymentID,
+	).Scan(&oldStatus)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback(ctx)
 
 	// Update payment status and paidat when becoming Paid
-	if newStatus == "Paid" {
-		query := `
-            UPDATE payments
-            SET paymentstatus = $1, paidat = NOW()
-            WHERE paymentid = $2;
-        `
-		if _, err := tx.Exec(ctx, query, newStatus, paymentID); err != nil {
-			return err
-		}
-	} else {
-		query := `
-            UPDATE payments
-            SET paymentstatus = $1
-            WHERE paymentid = $2;
-        `
-		if _, err := tx.Exec(ctx, query, newStatus, paymentID); err != nil {
-			return err
-		}
+	query := `
+        UPDATE payments
+        SET paymentstatus = $1,
+            paidat = CASE WHEN $1 = 'Paid' THEN NOW() ELSE paidat END
+        WHERE paymentid = $2;
+    `
+	if _, err := tx.Exec(ctx, query, newStatus, paymentID); err != nil {
+		return err
 	}
 
 	// Insert log
@@ -175,14 +183,60 @@ func UpdatePaymentStatus(ctx context.Context, db *pgxpool.Pool, paymentID int, n
         INSERT INTO paymentlogs (paymentid, oldstatus, newstatus)
         VALUES ($1, $2, $3);
     `
-	if _, err := tx.Exec(ctx, logQuery, paymentID, cur.PaymentStatus, newStatus); err != nil {
-		return err
+	_, err = tx.Exec(ctx, logQuery, paymentID, oldStatus, newStatus)
+	return err
+}
+
+/*
+ConfirmOrderPayment – marks the order paid, the payment Paid and grants the
+order's games in one transaction. The order is claimed first, so an order
+cancelled or expired in the meantime is never charged without its games.
+*/
+func ConfirmOrderPayment(ctx context.Context, db *pgxpool.Pool, paymentID int) (orderID int, err error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, err
 	}
+	defer tx.Rollback(ctx)
 
-	if err := tx.Commit(ctx); err != nil {
-		return err
+	var status string
+	err = tx.QueryRow(ctx,
+		`SELECT orderid, paymentstatus FROM payments WHERE paymentid = $1 FOR UPDATE`,
+		paymentID,
+	).Scan(&orderID, &status)
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	if status == "Paid" {
+		return 0, errors.New("payment already paid")
+	}
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE orders SET status = 'paid'
+		 WHERE orderid = $1 AND status = 'pending'`,
+		orderID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if tag.RowsAffected() == 0 {
+		var orderStatus string
+		if err := tx.QueryRow(ctx, `SELECT status FROM orders WHERE orderid = $1`, orderID).Scan(&orderStatus); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("order is %s and cannot be paid", orderStatus)
+	}
+
+	if err := setPaymentStatus(ctx, tx, paymentID, "Paid"); err != nil {
+		return 0, err
+	}
+
+	// unreleased games stay locked until release day
+	if err := grantEntitlementsForOrder(ctx, tx, orderID); err != nil {
+		return 0, err
+	}
+
+	return orderID, tx.Commit(ctx)
 }
 
 // GetPaymentMethodByID (helper)
@@ -199,12 +253,16 @@ func GetPaymentMethodByID(ctx context.Context, db *pgxpool.Pool, methodID int) (
 	return &m, nil
 }
 
-func GetAllTransactions(ctx context.Context, db *pgxpool.Pool) ([]AdminTransaction, error) {
+func GetAllTransactions(ctx context.Context, db *pgxpool.Pool, baseCurrency string) ([]AdminTransaction, error) {
 	query := `
         SELECT 
             o.orderid,
             o.customerid,
             o.totalprice,
+            COALESCE(o.currencycode, $1),
+            ROUND(o.totalprice / o.exchangerate, 2),
+            o.taxtotal,
+            ROUND(o.taxtotal / o.exchangerate, 2),
             o.orderdate,
             p.paymentstatus,
             p.paidat
@@ -215,7 +273,7 @@ func GetAllTransactions(ctx context.Context, db *pgxpool.Pool) ([]AdminTransacti
         ORDER BY o.orderdate ASC;
     `
 
-	rows, err := db.Query(ctx, query)
+	rows, err := db.Query(ctx, query, baseCurrency)
 	if err != nil {
 		return nil, err
 	}
@@ -228,6 +286,10 @@ func GetAllTransactions(ctx context.Context, db *pgxpool.Pool) ([]AdminTransacti
 			&t.OrderID,
 			&t.CustomerID,
 			&t.TotalPrice,
+			&t.CurrencyCode,
+			&t.BaseTotal,
+			&t.TaxTotal,
+			&t.BaseTaxTotal,
 			&t.OrderDate,
 			&t.PaymentStatus,
 			&t.PaidAt,
@@ -239,3 +301,28 @@ func GetAllTransactions(ctx context.Context, db *pgxpool.Pool) ([]AdminTransacti
 
 	return list, nil
 }
+
+// GetPaymentLogs returns the status changes of a payment, oldest first
+func GetPaymentLogs(ctx context.Context, db *pgxpool.Pool, paymentID int) ([]PaymentLog, error) {
+	query := `
+        SELECT logid, paymentid, COALESCE(oldstatus, ''), COALESCE(newstatus, ''), changedat
+        FROM paymentlogs
+        WHERE paymentid = $1
+        ORDER BY changedat, logid;
+    `
+	rows, err := db.Query(ctx, query, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []PaymentLog{}
+	for rows.Next() {
+		var l PaymentLog
+		if err := rows.Scan(&l.LogID, &l.PaymentID, &l.OldStatus, &l.NewStatus, &l.ChangedAt); err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, rows.Err()
+}
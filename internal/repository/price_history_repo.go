@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PricePoint struct {
+	Price      float64 // effective price at that moment
+	BasePrice  float64
+	Reason     string
+	RecordedAt time.Time
+}
+
+const recordPriceHistorySQL = `
+        INSERT INTO game_price_history (gameid, price, baseprice, reason)
+        SELECT g.gameid, ` + effectivePriceSQL + `, g.price, $2
+        FROM games g` + activeDiscountJoin + `
+        WHERE g.gameid = $1;
+    `
+
+// recordPriceHistory snapshots the current effective price of a game,
+// in the transaction that changed it
+func recordPriceHistory(ctx context.Context, tx pgx.Tx, gameID int, reason string) error {
+	_, err := tx.Exec(ctx, recordPriceHistorySQL, gameID, reason)
+	return err
+}
+
+// recordDeveloperPriceHistory snapshots every game of a developer (catalog-wide sales)
+func recordDeveloperPriceHistory(ctx context.Context, tx pgx.Tx, devID int, reason string) error {
+	query := `
+        INSERT INTO game_price_history (gameid, price, baseprice, reason)
+        SELECT g.gameid, ` + effectivePriceSQL + `, g.price, $2
+        FROM games g` + activeDiscountJoin + `
+        WHERE g.developerid = $1
+          AND g.deleted_at IS NULL;
+    `
+	_, err := tx.Exec(ctx, query, devID, reason)
+	return err
+}
+
+func GetPriceHistory(ctx context.Context, db *pgxpool.Pool, gameID int) ([]PricePoint, error) {
+	query := `
+        SELECT price, baseprice, reason, recorded_at
+        FROM game_price_history
+        WHERE gameid = $1
+        ORDER BY recorded_at, historyid;
+    `
+
+	rows, err := db.Query(ctx, query, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []PricePoint{}
+	for rows.Next() {
+		var p PricePoint
+		if err := rows.Scan(&p.Price, &p.BasePrice, &p.Reason, &p.RecordedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+
+	return list, rows.Err()
+}
+
+/*
+GetLowestPrice30Days – lowest effective price in the 30 days before the current
+sale started (or before now when the game is not on sale), including the price
+that was already in effect when that window opened. The sale price itself is
+left out, so it can be compared against. Nil if no history.
+*/
+func GetLowestPrice30Days(ctx context.Context, db *pgxpool.Pool, gameID int) (*float64, error) {
+	query := `
+        WITH sale AS (
+            SELECT COALESCE(MIN(d.startsat), NOW()) AS started
+            FROM games g
+            JOIN discounts d
+              ON d.developerid = g.developerid
+             AND (d.gameid IS NULL OR d.gameid = g.gameid)
+            WHERE g.gameid = $1
+              AND d.startsat <= NOW()
+              AND d.endsat > NOW()
+              AND d.deleted_at IS NULL
+        )
+        SELECT MIN(price)
+        FROM (
+            SELECT h.price
+            FROM game_price_history h, sale
+            WHERE h.gameid = $1
+              AND h.recorded_at >= sale.started - INTERVAL '30 days'
+              AND h.recorded_at < sale.started
+            UNION ALL
+            (
+                SELECT h.price
+                FROM game_price_history h, sale
+                WHERE h.gameid = $1
+                  AND h.recorded_at < sale.started - INTERVAL '30 days'
+                ORDER BY h.recorded_at DESC, h.historyid DESC
+                LIMIT 1
+            )
+        ) window_prices;
+    `
+
+	var lowest *float64
+	if err := db.QueryRow(ctx, query, gameID).Scan(&lowest); err != nil {
+		return nil, err
+	}
+	return lowest, nil
+}
+
+/*
+ActivateDueDiscounts – marks discounts whose start time has passed as active,
+records the new prices in the same transaction and returns the discounts
+*/
+func ActivateDueDiscounts(ctx context.Context, db *pgxpool.Pool) ([]Discount, error) {
+	query := `
+        UPDATE discounts
+        SET activated_at = NOW()
+        WHERE activated_at IS NULL
+          AND startsat <= NOW()
+          AND endsat > NOW()
+          AND deleted_at IS NULL
+        RETURNING discountid, developerid, gameid, percentoff, startsat, endsat;
+    `
+	return applyDiscountChanges(ctx, db, query, "discount started")
+}
+
+/*
+ConcludeEndedDiscounts – marks active discounts that expired or were cancelled
+as concluded, records the restored prices in the same transaction and returns them
+*/
+func ConcludeEndedDiscounts(ctx context.Context, db *pgxpool.Pool) ([]Discount, error) {
+	query := `
+        UPDATE discounts
+        SET concluded_at = NOW()
+        WHERE activated_at IS NOT NULL
+          AND concluded_at IS NULL
+          AND (endsat <= NOW() OR deleted_at IS NOT NULL)
+        RETURNING discountid, developerid, gameid, percentoff, startsat, endsat;
+    `
+	return applyDiscountChanges(ctx, db, query, "discount ended")
+}
+
+// applyDiscountChanges runs a discount state change and records a price
+// history entry for every game it affects, all in one transaction
+func applyDiscountChanges(ctx context.Context, db *pgxpool.Pool, query, reason string) ([]Discount, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Discount
+	for rows.Next() {
+		var d Discount
+		if err := rows.Scan(
+			&d.DiscountID,
+			&d.DeveloperID,
+			&d.GameID,
+			&d.PercentOff,
+			&d.StartsAt,
+			&d.EndsAt,
+		); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, d := range list {
+		if d.GameID != nil {
+			err = recordPriceHistory(ctx, tx, *d.GameID, reason)
+		} else {
+			err = recordDeveloperPriceHistory(ctx, tx, d.DeveloperID, reason)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return list, tx.Commit(ctx)
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLowestPriceExcludesCurrentSale(t *testing.T) {
+	db := testPool(t)
+	ctx := context.Background()
+
+	gameID := createTestGame(t, db)
+
+	history := []struct {
+		price float64
+		ago   string
+	}{
+		{30, "60 days"}, // in effect when the window opened
+		{25, "20 days"},
+		{28, "10 days"},
+		{14, "1 day"}, // the sale price itself
+	}
+	for _, h := range history {
+		_, err := db.Exec(ctx,
+			`INSERT INTO game_price_history (gameid, price, baseprice, reason, recorded_at)
+			 VALUES ($1, $2, $2, 'test', NOW() - $3::interval)`,
+			gameID, h.price, h.ago,
+		)
+		if err != nil {
+			t.Fatalf("insert history: %v", err)
+		}
+	}
+
+	lowest, err := GetLowestPrice30Days(ctx, db, gameID)
+	if err != nil {
+		t.Fatalf("GetLowestPrice30Days: %v", err)
+	}
+	if lowest == nil || *lowest != 14 {
+		t.Fatalf("without a sale lowest = %v, want 14", lowest)
+	}
+
+	_, err = db.Exec(ctx,
+		`INSERT INTO discounts (developerid, gameid, percentoff, startsat, endsat)
+		 SELECT developerid, gameid, 50, NOW() - INTERVAL '1 day' - INTERVAL '1 minute', NOW() + INTERVAL '1 day'
+		 FROM games WHERE gameid = $1`,
+		gameID,
+	)
+	if err != nil {
+		t.Fatalf("insert discount: %v", err)
+	}
+
+	lowest, err = GetLowestPrice30Days(ctx, db, gameID)
+	if err != nil {
+		t.Fatalf("GetLowestPrice30Days: %v", err)
+	}
+	if lowest == nil || *lowest != 25 {
+		t.Fatalf("during the sale lowest = %v, want 25 from before it started", lowest)
+	}
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// paidPurchasesCTE lists each (customer, game) pair from paid orders
+const paidPurchasesCTE = `
+        purchases AS (
+            SELECT DISTINCT o.customerid, oi.gameid
+            FROM orderitems oi
+            JOIN orders o ON o.orderid = oi.orderid
+            JOIN payments p ON p.orderid = o.orderid AND p.paymentstatus = 'Paid'
+            WHERE oi.deleted_at IS NULL
+              AND o.deleted_at IS NULL
+        )
+`
+
+/*
+RebuildRecommendations – recomputes the co-purchase table and every customer's
+personalized picks (genre affinity + co-purchases of owned games) in one transaction
+*/
+func RebuildRecommendations(ctx context.Context, db *pgxpool.Pool) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM game_copurchases`); err != nil {
+		return err
+	}
+
+	copurchases := `
+        WITH ` + paidPurchasesCTE + `
+        INSERT INTO game_copurchases (gameid, relatedgameid, score)
+        SELECT a.gameid, b.gameid, COUNT(*)
+        FROM purchases a
+        JOIN purchases b ON b.customerid = a.customerid AND b.gameid <> a.gameid
+        GROUP BY a.gameid, b.gameid;
+    `
+	if _, err := tx.Exec(ctx, copurchases); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM customer_recommendations`); err != nil {
+		return err
+	}
+
+	personalized := `
+        WITH ` + paidPurchasesCTE + `,
+        affinity AS (
+            SELECT p.customerid, gg.genreid, COUNT(*) AS weight
+            FROM purchases p
+            JOIN gamegenres gg ON gg.gameid = p.gameid
+            JOIN genres ge ON ge.genreid = gg.genreid AND ge.deleted_at IS NULL
+            GROUP BY p.customerid, gg.genreid
+        ),
+        scores AS (
+            SELECT a.customerid, gg.gameid, a.weight AS score
+            FROM affinity a
+            JOIN gamegenres gg ON gg.genreid = a.genreid
+            UNION ALL
+            SELECT p.customerid, c.relatedgameid, c.score
+            FROM purchases p
+            JOIN game_copurchases c ON c.gameid = p.gameid
+        )
+        INSERT INTO customer_recommendations (customerid, gameid, score)
+        SELECT s.customerid, s.gameid, SUM(s.score)
+        FROM scores s
+        JOIN games g ON g.gameid = s.gameid AND g.deleted_at IS NULL AND g.hidden_at IS NULL
+        WHERE NOT EXISTS (
+            SELECT 1 FROM purchases p
+            WHERE p.customerid = s.customerid AND p.gameid = s.gameid
+        )
+        GROUP BY s.customerid, s.gameid;
+    `
+	if _, err := tx.Exec(ctx, personalized); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetAlsoBought returns the games most often bought together with gameID
+func GetAlsoBought(ctx context.Context, db *pgxpool.Pool, gameID, limit int) ([]GameList, error) {
+	query := `
+        SELECT g.gameid, g.title, ` + effectivePriceSQL + `,
+               COALESCE(disc.percentoff, 0),
+               g.releasedate, g.released_at IS NOT NULL
+        FROM game_copurchases c
+        JOIN games g ON g.gameid = c.relatedgameid` + activeDiscountJoin + `
+        WHERE c.gameid = $1
+          AND g.deleted_at IS NULL
+          AND g.hidden_at IS NULL
+        ORDER BY c.score DESC, g.gameid
+        LIMIT $2;
+    `
+	return queryRecommendedGames(ctx, db, query, gameID, limit)
+}
+
+// GetCustomerRecommendations returns the customer's personalized picks, best first
+func GetCustomerRecommendations(ctx context.Context, db *pgxpool.Pool, customerID, limit int) ([]GameList, error) {
+	query := `
+        SELECT g.gameid, g.title, ` + effectivePriceSQL + `,
+               COALESCE(disc.percentoff, 0),
+               g.releasedate, g.released_at IS NOT NULL
+        FROM customer_recommendations r
+        JOIN games g ON g.gameid = r.gameid` + activeDiscountJoin + `
+        WHERE r.customerid = $1
+          AND g.deleted_at IS NULL
+          AND g.hidden_at IS NULL
+        ORDER BY r.score DESC, g.gameid
+        LIMIT $2;
+    `
+	return queryRecommendedGames(ctx, db, query, customerID, limit)
+}
+
+func queryRecommendedGames(ctx context.Context, db *pgxpool.Pool, query string, id, limit int) ([]GameList, error) {
+	rows, err := db.Query(ctx, query, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []GameList{}
+	for rows.Next() {
+		var g GameList
+		if err := rows.Scan(&g.GameID, &g.Title, &g.Price, &g.DiscountPercent, &g.ReleaseDate, &g.Released); err != nil {
+			return nil, err
+		}
+		list = append(list, g)
+	}
+
+	return list, rows.Err()
+}
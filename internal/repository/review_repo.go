@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Review struct {
+	ReviewID       int
+	GameID         int
+	GameTitle      string
+	Username       string
+	Rating         int
+	Body           string
+	HelpfulCount   int
+	DeveloperReply *string
+	RepliedAt      *time.Time
+	HiddenAt       *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      *time.Time
+}
+
+// reviewSelect lists the columns scanned by scanReviews
+const reviewSelect = `
+        SELECT r.reviewid, r.gameid, g.title, COALESCE(c.username, ''),
+               r.rating, COALESCE(r.body, ''),
+               (SELECT COUNT(*) FROM review_helpful h WHERE h.reviewid = r.reviewid),
+               r.developerreply, r.replied_at, r.hidden_at,
+               r.created_at, r.updated_at
+        FROM reviews r
+        JOIN games g ON g.gameid = r.gameid
+        JOIN customers c ON c.customerid = r.customerid
+`
+
+func scanReviews(rows pgx.Rows) ([]Review, error) {
+	defer rows.Close()
+
+	list := []Review{}
+	for rows.Next() {
+		var r Review
+		if err := rows.Scan(
+			&r.ReviewID,
+			&r.GameID,
+			&r.GameTitle,
+			&r.Username,
+			&r.Rating,
+			&r.Body,
+			&r.HelpfulCount,
+			&r.DeveloperReply,
+			&r.RepliedAt,
+			&r.HiddenAt,
+			&r.CreatedAt,
+			&r.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+
+	return list, rows.Err()
+}
+
+/*
+UpsertReview – creates the customer's review of a game, or edits it if one exists.
+Re-posting a deleted review starts it over: its votes, developer reply,
+moderation and "(edited)" marker belong to the old text, so they are cleared.
+*/
+func UpsertReview(ctx context.Context, db *pgxpool.Pool, customerID, gameID, rating int, body string) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM review_helpful
+		 WHERE reviewid = (
+		     SELECT reviewid FROM reviews
+		     WHERE customerid = $1 AND gameid = $2 AND deleted_at IS NOT NULL
+		     FOR UPDATE
+		 )`,
+		customerID, gameID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO reviews (customerid, gameid, rating, body)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (customerid, gameid)
+		 DO UPDATE SET rating = EXCLUDED.rating,
+		               body = EXCLUDED.body,
+		               created_at = CASE WHEN reviews.deleted_at IS NULL THEN reviews.created_at ELSE NOW() END,
+		               updated_at = CASE WHEN reviews.deleted_at IS NULL THEN NOW() END,
+		               developerreply = CASE WHEN reviews.deleted_at IS NULL THEN reviews.developerreply END,
+		               replied_at = CASE WHEN reviews.deleted_at IS NULL THEN reviews.replied_at END,
+		               hidden_at = CASE WHEN reviews.deleted_at IS NULL THEN reviews.hidden_at END,
+		               deleted_at = NULL`,
+		customerID, gameID, rating, body,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func DeleteReview(ctx context.Context, db *pgxpool.Pool, customerID, gameID int) error {
+	tag, err := db.Exec(ctx,
+		`UPDATE reviews
+		 SET deleted_at = NOW()
+		 WHERE customerid = $1
+		   AND gameid = $2
+		   AND deleted_at IS NULL`,
+		customerID, gameID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("review not found")
+	}
+	return nil
+}
+
+// GetGameReviews returns the visible reviews of a game, most helpful first
+func GetGameReviews(ctx context.Context, db *pgxpool.Pool, gameID int) ([]Review, error) {
+	rows, err := db.Query(ctx, reviewSelect+`
+        WHERE r.gameid = $1
+          AND r.deleted_at IS NULL
+          AND r.hidden_at IS NULL
+        ORDER BY 7 DESC, r.created_at DESC;
+    `, gameID)
+	if err != nil {
+		return nil, err
+	}
+	return scanReviews(rows)
+}
+
+// GetReviewsForModeration returns every review, hidden ones included, newest first
+func GetReviewsForModeration(ctx context.Context, db *pgxpool.Pool) ([]Review, error) {
+	rows, err := db.Query(ctx, reviewSelect+`
+        WHERE r.deleted_at IS NULL
+        ORDER BY r.created_at DESC;
+    `)
+	if err != nil {
+		return nil, err
+	}
+	return scanReviews(rows)
+}
+
+func GetGameRating(ctx context.Context, db *pgxpool.Pool, gameID int) (float64, int, error) {
+	var avg float64
+	var count int
+	err := db.QueryRow(ctx,
+		`SELECT COALESCE(AVG(rating), 0), COUNT(*)
+		 FROM reviews
+		 WHERE gameid = $1
+		   AND deleted_at IS NULL
+		   AND hidden_at IS NULL`,
+		gameID,
+	).Scan(&avg, &count)
+	return avg, count, err
+}
+
+/*
+MarkReviewHelpful – customers can't vote on their own reviews; repeat votes are ignored
+*/
+func MarkReviewHelpful(ctx context.Context, db *pgxpool.Pool, customerID, reviewID int) error {
+	var authorID int
+	err := db.QueryRow(ctx,
+		`SELECT customerid FROM reviews
+		 WHERE reviewid = $1 AND deleted_at IS NULL AND hidden_at IS NULL`,
+		reviewID,
+	).Scan(&authorID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.New("review not found")
+		}
+		return err
+	}
+	if authorID == customerID {
+		return errors.New("you cannot mark your own review as helpful")
+	}
+
+	_, err = db.Exec(ctx,
+		`INSERT INTO review_helpful (reviewid, customerid)
+		 VALUES ($1, $2)
+		 ON CONFLICT (reviewid, customerid) DO NOTHING`,
+		reviewID, customerID,
+	)
+	return err
+}
+
+/*
+ReplyToReview – only the developer of the reviewed game may reply
+*/
+func ReplyToReview(ctx context.Context, db *pgxpool.Pool, devID, reviewID int, reply string) error {
+	tag, err := db.Exec(ctx,
+		`UPDATE reviews r
+		 SET developerreply = $1, replied_at = NOW()
+		 FROM games g
+		 WHERE g.gameid = r.gameid
+		   AND g.developerid = $2
+		   AND r.reviewid = $3
+		   AND r.deleted_at IS NULL`,
+		reply, devID, reviewID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("review not found on any of your games")
+	}
+	return nil
+}
+
+func SetReviewHidden(ctx context.Context, tx pgx.Tx, reviewID int, hidden bool) error {
+	query := `UPDATE reviews SET hidden_at = NULL WHERE reviewid = $1 AND deleted_at IS NULL`
+	if hidden {
+		query = `UPDATE reviews SET hidden_at = NOW() WHERE reviewid = $1 AND deleted_at IS NULL`
+	}
+
+	tag, err := tx.Exec(ctx, query, reviewID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("review not found")
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUpsertReviewRepostStartsOver(t *testing.T) {
+	db := testPool(t)
+	ctx := context.Background()
+
+	author := createTestCustomer(t, db)
+	voter := createTestCustomer(t, db)
+	gameID := createTestGame(t, db)
+
+	if err := UpsertReview(ctx, db, author, gameID, 2, "first take"); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	var reviewID int
+	if err := db.QueryRow(ctx,
+		`SELECT reviewid FROM reviews WHERE customerid = $1 AND gameid = $2`, author, gameID,
+	).Scan(&reviewID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UpsertReview(ctx, db, author, gameID, 3, "edited take"); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if err := MarkReviewHelpful(ctx, db, voter, reviewID); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	if _, err := db.Exec(ctx,
+		`UPDATE reviews SET developerreply = 'thanks', replied_at = NOW(), hidden_at = NOW() WHERE reviewid = $1`,
+		reviewID,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	// an edit keeps everything attached to the review
+	if err := UpsertReview(ctx, db, author, gameID, 4, "edited again"); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	var votes int
+	var edited, replied, hidden bool
+	check := func() {
+		t.Helper()
+		if err := db.QueryRow(ctx,
+			`SELECT (SELECT COUNT(*) FROM review_helpful WHERE reviewid = r.reviewid),
+			        r.updated_at IS NOT NULL, r.developerreply IS NOT NULL, r.hidden_at IS NOT NULL
+			 FROM reviews r WHERE r.reviewid = $1`,
+			reviewID,
+		).Scan(&votes, &edited, &replied, &hidden); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check()
+	if votes != 1 || !edited || !replied || !hidden {
+		t.Errorf("after edit: votes=%d edited=%v replied=%v hidden=%v, want all kept", votes, edited, replied, hidden)
+	}
+
+	// a re-post after deleting is a new review
+	if err := DeleteReview(ctx, db, author, gameID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := UpsertReview(ctx, db, author, gameID, 5, "second take"); err != nil {
+		t.Fatalf("repost: %v", err)
+	}
+	check()
+	if votes != 0 || edited || replied || hidden {
+		t.Errorf("after repost: votes=%d edited=%v replied=%v hidden=%v, want all cleared", votes, edited, replied, hidden)
+	}
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Suspension struct {
+	SuspensionID int
+	AuthID       int
+	Reason       string
+	SuspendedBy  *string // admin email, nil if that account is gone
+	CreatedAt    time.Time
+	ExpiresAt    *time.Time // nil for a permanent suspension
+	HideGames    bool
+	LiftedAt     *time.Time
+	LiftedBy     *string // nil when lifted by expiry
+	GamesHidden  int
+}
+
+const suspensionSelect = `
+        SELECT s.suspensionid, s.authid, s.reason, sb.email, s.created_at, s.expires_at,
+               s.hidegames, s.lifted_at, lb.email,
+               (SELECT COUNT(*) FROM suspension_games sg WHERE sg.suspensionid = s.suspensionid)
+        FROM suspensions s
+        LEFT JOIN userauth sb ON sb.authid = s.suspendedby
+        LEFT JOIN userauth lb ON lb.authid = s.liftedby
+`
+
+func scanSuspensions(rows pgx.Rows) ([]Suspension, error) {
+	defer rows.Close()
+
+	list := []Suspension{}
+	for rows.Next() {
+		var s Suspension
+		if err := rows.Scan(&s.SuspensionID, &s.AuthID, &s.Reason, &s.SuspendedBy, &s.CreatedAt, &s.ExpiresAt,
+			&s.HideGames, &s.LiftedAt, &s.LiftedBy, &s.GamesHidden); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// GetActiveSuspension returns nil when the account is not suspended
+func GetActiveSuspension(ctx context.Context, db *pgxpool.Pool, authID int) (*Suspension, error) {
+	rows, err := db.Query(ctx, suspensionSelect+` WHERE s.authid = $1 AND s.lifted_at IS NULL`, authID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := scanSuspensions(rows)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+func GetSuspensionHistory(ctx context.Context, db *pgxpool.Pool, authID int) ([]Suspension, error) {
+	rows, err := db.Query(ctx, suspensionSelect+` WHERE s.authid = $1 ORDER BY s.created_at DESC`, authID)
+	if err != nil {
+		return nil, err
+	}
+	return scanSuspensions(rows)
+}
+
+/*
+GetSuspensionForLogin – for an email whose account is suspended, returns the
+password hash (so the reason is only shown to the owner) and the suspension
+*/
+func GetSuspensionForLogin(ctx context.Context, db *pgxpool.Pool, email string) (string, *Suspension, error) {
+	var hash string
+	var s Suspension
+	err := db.QueryRow(ctx,
+		`SELECT ua.passwordhash, s.suspensionid, s.authid, s.reason, s.created_at, s.expires_at
+		 FROM userauth ua
+		 JOIN suspensions s ON s.authid = ua.authid AND s.lifted_at IS NULL
+		 WHERE ua.email = $1
+		   AND ua.deleted_at IS NOT NULL`,
+		email,
+	).Scan(&hash, &s.SuspensionID, &s.AuthID, &s.Reason, &s.CreatedAt, &s.ExpiresAt)
+	if err == pgx.ErrNoRows {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return hash, &s, nil
+}
+
+/*
+SuspendAccount – blocks login by setting userauth.deleted_at and records why.
+With hideGames, a developer's live games are hidden from the store (not removed:
+owners keep them and pre-orders still unlock on release) and remembered so
+lifting the suspension brings back exactly those. Returns the new suspension.
+*/
+func SuspendAccount(ctx context.Context, tx pgx.Tx, authID, actorAuthID int, reason string, expiresAt *time.Time, hideGames bool) (*Suspension, error) {
+	tag, err := tx.Exec(ctx,
+		`UPDATE userauth SET deleted_at = NOW() WHERE authid = $1 AND deleted_at IS NULL`,
+		authID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, errors.New("account is already suspended or does not exist")
+	}
+
+	var suspensionID int
+	err = tx.QueryRow(ctx,
+		`INSERT INTO suspensions (authid, reason, suspendedby, expires_at, hidegames)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING suspensionid`,
+		authID, reason, actorAuthID, expiresAt, hideGames,
+	).Scan(&suspensionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hideGames {
+		_, err = tx.Exec(ctx,
+			`WITH hidden AS (
+			     UPDATE games g SET hidden_at = NOW()
+			     FROM developers d
+			     WHERE d.developerid = g.developerid
+			       AND d.authid = $1
+			       AND g.deleted_at IS NULL
+			       AND g.hidden_at IS NULL
+			     RETURNING g.gameid
+			 )
+			 INSERT INTO suspension_games (suspensionid, gameid)
+			 SELECT $2, gameid FROM hidden`,
+			authID, suspensionID,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := tx.Query(ctx, suspensionSelect+` WHERE s.suspensionid = $1`, suspensionID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := scanSuspensions(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+/*
+LiftSuspension – restores login and any games the suspension hid.
+actorAuthID is 0 when the suspension simply expired.
+*/
+func LiftSuspension(ctx context.Context, tx pgx.Tx, authID, actorAuthID int) error {
+	var suspensionID int
+	err := tx.QueryRow(ctx,
+		`UPDATE suspensions SET lifted_at = NOW(), liftedby = NULLIF($2, 0)
+		 WHERE authid = $1 AND lifted_at IS NULL
+		 RETURNING suspensionid`,
+		authID, actorAuthID,
+	).Scan(&suspensionID)
+	if err == pgx.ErrNoRows {
+		// accounts banned before suspensions existed only have deleted_at set
+		tag, err := tx.Exec(ctx,
+			`UPDATE userauth SET deleted_at = NULL WHERE authid = $1 AND deleted_at IS NOT NULL`,
+			authID,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return errors.New("account is not suspended")
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE userauth SET deleted_at = NULL WHERE authid = $1`, authID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE games g SET hidden_at = NULL
+		 FROM suspension_games sg
+		 WHERE sg.gameid = g.gameid
+		   AND sg.suspensionid = $1`,
+		suspensionID,
+	)
+	return err
+}
+
+// GetExpiredSuspensions lists accounts whose suspension has run out but is not lifted yet
+func GetExpiredSuspensions(ctx context.Context, db *pgxpool.Pool) ([]int, error) {
+	rows, err := db.Query(ctx,
+		`SELECT authid FROM suspensions
+		 WHERE lifted_at IS NULL AND expires_at <= NOW()
+		 ORDER BY expires_at`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSuspensionHidesGamesWithoutRemovingThem(t *testing.T) {
+	db := testPool(t)
+	ctx := context.Background()
+
+	var authID, devID int
+	err := db.QueryRow(ctx,
+		`INSERT INTO userauth (email, passwordhash, role) VALUES ($1, 'x', 'developer') RETURNING authid`,
+		uniqueName("dev")+"@example.test",
+	).Scan(&authID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(ctx,
+		`INSERT INTO developers (developername, authid) VALUES ($1, $2) RETURNING developerid`,
+		uniqueName("dev"), authID,
+	).Scan(&devID); err != nil {
+		t.Fatal(err)
+	}
+
+	var kept, removed int
+	for _, id := range []*int{&kept, &removed} {
+		if err := db.QueryRow(ctx,
+			`INSERT INTO games (developerid, title, price, releasedate, released_at)
+			 VALUES ($1, $2, 10, CURRENT_DATE, NOW()) RETURNING gameid`,
+			devID, uniqueName("game"),
+		).Scan(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	state := func(gameID int) (hidden, deleted bool) {
+		t.Helper()
+		if err := db.QueryRow(ctx,
+			`SELECT hidden_at IS NOT NULL, deleted_at IS NOT NULL FROM games WHERE gameid = $1`, gameID,
+		).Scan(&hidden, &deleted); err != nil {
+			t.Fatal(err)
+		}
+		return hidden, deleted
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SuspendAccount(ctx, tx, authID, 0, "test", nil, true); err != nil {
+		t.Fatalf("suspend: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if hidden, deleted := state(kept); !hidden || deleted {
+		t.Errorf("while suspended: hidden=%v deleted=%v, want hidden and not deleted", hidden, deleted)
+	}
+	if _, err := GetGamePrice(ctx, db, kept); err == nil {
+		t.Error("a hidden game can still be priced for purchase")
+	}
+
+	// removed for its own reasons while the developer is suspended
+	if _, err := db.Exec(ctx, `UPDATE games SET deleted_at = NOW() WHERE gameid = $1`, removed); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err = db.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LiftSuspension(ctx, tx, authID, 0); err != nil {
+		t.Fatalf("lift: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if hidden, deleted := state(kept); hidden || deleted {
+		t.Errorf("after lift: hidden=%v deleted=%v, want back on the store", hidden, deleted)
+	}
+	if _, deleted := state(removed); !deleted {
+		t.Error("lifting the suspension restored a game that was removed during it")
+	}
+}
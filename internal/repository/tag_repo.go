@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TagCount struct {
+	TagID         int
+	TagName       string
+	Votes         int
+	BlacklistedAt *time.Time
+}
+
+/*
+ApplyTag – creates the tag if needed and records the account's vote for it on the game
+*/
+func ApplyTag(ctx context.Context, db *pgxpool.Pool, authID, gameID int, name string) error {
+	var tagID int
+	var blacklisted *time.Time
+	err := db.QueryRow(ctx,
+		`INSERT INTO tags (tagname)
+		 VALUES ($1)
+		 ON CONFLICT (tagname) DO UPDATE SET tagname = EXCLUDED.tagname
+		 RETURNING tagid, blacklisted_at`,
+		name,
+	).Scan(&tagID, &blacklisted)
+	if err != nil {
+		return err
+	}
+	if blacklisted != nil {
+		return errors.New("this tag is not allowed")
+	}
+
+	_, err = db.Exec(ctx,
+		`INSERT INTO gametags (gameid, tagid, authid)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (gameid, tagid, authid) DO NOTHING`,
+		gameID, tagID, authID,
+	)
+	return err
+}
+
+func RemoveTagVote(ctx context.Context, db *pgxpool.Pool, authID, gameID, tagID int) error {
+	tag, err := db.Exec(ctx,
+		`DELETE FROM gametags
+		 WHERE gameid = $1 AND tagid = $2 AND authid = $3`,
+		gameID, tagID, authID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("you have not applied this tag")
+	}
+	return nil
+}
+
+// GetGameTopTags returns the most voted, non-blacklisted tags of a game
+func GetGameTopTags(ctx context.Context, db *pgxpool.Pool, gameID, limit int) ([]TagCount, error) {
+	query := `
+        SELECT t.tagid, t.tagname, COUNT(*) AS votes, t.blacklisted_at
+        FROM gametags gt
+        JOIN tags t ON t.tagid = gt.tagid
+        WHERE gt.gameid = $1
+          AND t.blacklisted_at IS NULL
+        GROUP BY t.tagid, t.tagname, t.blacklisted_at
+        ORDER BY votes DESC, t.tagname
+        LIMIT $2;
+    `
+	return queryTagCounts(ctx, db, query, gameID, limit)
+}
+
+// SearchTags finds non-blacklisted tags by name, with their total votes
+func SearchTags(ctx context.Context, db *pgxpool.Pool, term string) ([]TagCount, error) {
+	query := `
+        SELECT t.tagid, t.tagname, COUNT(gt.gameid) AS votes, t.blacklisted_at
+        FROM tags t
+        LEFT JOIN gametags gt ON gt.tagid = t.tagid
+        WHERE t.blacklisted_at IS NULL
+          AND t.tagname ILIKE '%' || $1 || '%'
+        GROUP BY t.tagid, t.tagname, t.blacklisted_at
+        ORDER BY votes DESC, t.tagname;
+    `
+	return queryTagCounts(ctx, db, query, term)
+}
+
+// GetAllTags lists every tag, blacklisted ones included, for moderation
+func GetAllTags(ctx context.Context, db *pgxpool.Pool) ([]TagCount, error) {
+	query := `
+        SELECT t.tagid, t.tagname, COUNT(gt.gameid) AS votes, t.blacklisted_at
+        FROM tags t
+        LEFT JOIN gametags gt ON gt.tagid = t.tagid
+        GROUP BY t.tagid, t.tagname, t.blacklisted_at
+        ORDER BY t.tagname;
+    `
+	return queryTagCounts(ctx, db, query)
+}
+
+func queryTagCounts(ctx context.Context, db *pgxpool.Pool, query string, args ...any) ([]TagCount, error) {
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []TagCount{}
+	for rows.Next() {
+		var t TagCount
+		if err := rows.Scan(&t.TagID, &t.TagName, &t.Votes, &t.BlacklistedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+
+	return list, rows.Err()
+}
+
+// GetGamesByTag lists games carrying a non-blacklisted tag, most voted first
+func GetGamesByTag(ctx context.Context, db *pgxpool.Pool, tagID int) ([]GameList, error) {
+	query := `
+        SELECT g.gameid, g.title, ` + effectivePriceSQL + `,
+               COALESCE(disc.percentoff, 0),
+               g.releasedate, g.released_at IS NOT NULL
+        FROM games g` + activeDiscountJoin + `
+        JOIN (
+            SELECT gt.gameid, COUNT(*) AS votes
+            FROM gametags gt
+            JOIN tags t ON t.tagid = gt.tagid
+            WHERE gt.tagid = $1
+              AND t.blacklisted_at IS NULL
+            GROUP BY gt.gameid
+        ) tagged ON tagged.gameid = g.gameid
+        WHERE g.deleted_at IS NULL
+          AND g.hidden_at IS NULL
+        ORDER BY tagged.votes DESC, g.gameid;
+    `
+
+	rows, err := db.Query(ctx, query, tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []GameList{}
+	for rows.Next() {
+		var g GameList
+		if err := rows.Scan(&g.GameID, &g.Title, &g.Price, &g.DiscountPercent, &g.ReleaseDate, &g.Released); err != nil {
+			return nil, err
+		}
+		list = append(list, g)
+	}
+
+	return list, rows.Err()
+}
+
+func SetTagBlacklisted(ctx context.Context, tx pgx.Tx, tagID int, blacklisted bool) error {
+	query := `UPDATE tags SET blacklisted_at = NULL WHERE tagid = $1`
+	if blacklisted {
+		query = `UPDATE tags SET blacklisted_at = NOW() WHERE tagid = $1`
+	}
+
+	tag, err := tx.Exec(ctx, query, tagID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("tag not found")
+	}
+	return nil
+}
+
+// GetTagID returns the ID of the tag with that name, or 0
+func GetTagID(ctx context.Context, tx pgx.Tx, name string) (int, error) {
+	var id int
+	err := tx.QueryRow(ctx, `SELECT tagid FROM tags WHERE tagname = $1`, name).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// BlacklistTagName blacklists a tag by name, creating it so it can never be applied
+func BlacklistTagName(ctx context.Context, tx pgx.Tx, name string) (int, error) {
+	var id int
+	err := tx.QueryRow(ctx,
+		`INSERT INTO tags (tagname, blacklisted_at)
+		 VALUES ($1, NOW())
+		 ON CONFLICT (tagname) DO UPDATE SET blacklisted_at = NOW()
+		 RETURNING tagid`,
+		name,
+	).Scan(&id)
+	return id, err
+}
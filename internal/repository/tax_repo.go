@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TaxRate – a nil Region applies to the whole country
+type TaxRate struct {
+	TaxRateID   int
+	Country     string
+	Region      *string
+	TaxName     string
+	RatePercent float64
+}
+
+func GetTaxRates(ctx context.Context, db *pgxpool.Pool) ([]TaxRate, error) {
+	rows, err := db.Query(ctx,
+		`SELECT taxrateid, country, region, taxname, ratepercent
+		 FROM tax_rates
+		 ORDER BY lower(country), region NULLS FIRST`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []TaxRate{}
+	for rows.Next() {
+		var t TaxRate
+		if err := rows.Scan(&t.TaxRateID, &t.Country, &t.Region, &t.TaxName, &t.RatePercent); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+
+	return list, rows.Err()
+}
+
+/*
+FindTaxRate – the region's rate if one exists, otherwise the country-wide rate.
+Returns nil when the location is not taxed.
+*/
+func FindTaxRate(ctx context.Context, db *pgxpool.Pool, country, region string) (*TaxRate, error) {
+	var t TaxRate
+	err := db.QueryRow(ctx,
+		`SELECT taxrateid, country, region, taxname, ratepercent
+		 FROM tax_rates
+		 WHERE lower(country) = lower($1)
+		   AND (region IS NULL OR lower(region) = lower($2))
+		 ORDER BY region IS NULL
+		 LIMIT 1`,
+		country, region,
+	).Scan(&t.TaxRateID, &t.Country, &t.Region, &t.TaxName, &t.RatePercent)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetTaxRateID returns the ID of the rate for exactly this country and region, or 0
+func GetTaxRateID(ctx context.Context, tx pgx.Tx, country string, region *string) (int, error) {
+	var id int
+	err := tx.QueryRow(ctx,
+		`SELECT taxrateid FROM tax_rates
+		 WHERE lower(country) = lower($1)
+		   AND COALESCE(lower(region), '') = COALESCE(lower($2), '')`,
+		country, region,
+	).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// UpsertTaxRate returns the ID of the added or updated rate
+func UpsertTaxRate(ctx context.Context, tx pgx.Tx, country string, region *string, name string, rate float64) (int, error) {
+	var id int
+	err := tx.QueryRow(ctx,
+		`INSERT INTO tax_rates (country, region, taxname, ratepercent)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (lower(country), COALESCE(lower(region), ''))
+		 DO UPDATE SET taxname = EXCLUDED.taxname,
+		               ratepercent = EXCLUDED.ratepercent
+		 RETURNING taxrateid`,
+		country, region, name, rate,
+	).Scan(&id)
+	return id, err
+}
+
+func RemoveTaxRate(ctx context.Context, tx pgx.Tx, taxRateID int) error {
+	tag, err := tx.Exec(ctx, `DELETE FROM tax_rates WHERE taxrateid = $1`, taxRateID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("tax rate not found")
+	}
+	return nil
+}
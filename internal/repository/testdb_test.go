@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testPool connects to TEST_DATABASE_URL, a scratch database with ddl.sql
+// applied. Tests that need it are skipped when it is not set.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// uniqueName returns a name no other test run has used
+func uniqueName(prefix string) string {
+	return fmt.Sprintf("%s%d", prefix, time.Now().UnixNano())
+}
+
+// createTestCustomer inserts a user account and its customer row
+func createTestCustomer(t *testing.T, db *pgxpool.Pool) (customerID int) {
+	t.Helper()
+	ctx := context.Background()
+
+	name := uniqueName("c")
+	email := name + "@example.test"
+
+	var authID int
+	err := db.QueryRow(ctx,
+		`INSERT INTO userauth (email, passwordhash, role) VALUES ($1, 'x', 'user') RETURNING authid`,
+		email,
+	).Scan(&authID)
+	if err != nil {
+		t.Fatalf("insert userauth: %v", err)
+	}
+
+	err = db.QueryRow(ctx,
+		`INSERT INTO customers (authid, email, username) VALUES ($1, $2, $3) RETURNING customerid`,
+		authID, email, name[len(name)-20:],
+	).Scan(&customerID)
+	if err != nil {
+		t.Fatalf("insert customer: %v", err)
+	}
+	return customerID
+}
+
+// createTestGame inserts a released game for a new developer
+func createTestGame(t *testing.T, db *pgxpool.Pool) (gameID int) {
+	t.Helper()
+	ctx := context.Background()
+
+	var devID int
+	err := db.QueryRow(ctx,
+		`INSERT INTO developers (developername) VALUES ($1) RETURNING developerid`,
+		uniqueName("dev"),
+	).Scan(&devID)
+	if err != nil {
+		t.Fatalf("insert developer: %v", err)
+	}
+
+	err = db.QueryRow(ctx,
+		`INSERT INTO games (developerid, title, price, releasedate, released_at)
+		 VALUES ($1, $2, 10, CURRENT_DATE, NOW()) RETURNING gameid`,
+		devID, uniqueName("game"),
+	).Scan(&gameID)
+	if err != nil {
+		t.Fatalf("insert game: %v", err)
+	}
+	return gameID
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TOTP struct {
+	Secret    string // base32
+	EnabledAt *time.Time
+	LastStep  int64 // last accepted time step, so a code can't be replayed
+}
+
+// GetTOTP returns nil when the account never started enrollment
+func GetTOTP(ctx context.Context, db *pgxpool.Pool, authID int) (*TOTP, error) {
+	var t TOTP
+	err := db.QueryRow(ctx,
+		`SELECT secret, enabled_at, last_step FROM user_totp WHERE authid = $1`,
+		authID,
+	).Scan(&t.Secret, &t.EnabledAt, &t.LastStep)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+/*
+SaveTOTPSecret – starts (or restarts) enrollment with a new secret.
+An enabled secret is never overwritten.
+*/
+func SaveTOTPSecret(ctx context.Context, db *pgxpool.Pool, authID int, secret string) error {
+	tag, err := db.Exec(ctx,
+		`INSERT INTO user_totp (authid, secret)
+		 VALUES ($1, $2)
+		 ON CONFLICT (authid) DO UPDATE
+		 SET secret = EXCLUDED.secret, last_step = 0, created_at = NOW()
+		 WHERE user_totp.enabled_at IS NULL`,
+		authID, secret,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("two-factor authentication is already enabled")
+	}
+	return nil
+}
+
+func insertRecoveryCodes(ctx context.Context, tx pgx.Tx, authID int, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM totp_recovery_codes WHERE authid = $1`, authID); err != nil {
+		return err
+	}
+	for _, h := range codeHashes {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO totp_recovery_codes (authid, codehash) VALUES ($1, $2)`,
+			authID, h,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnableTOTP finishes enrollment and stores a fresh set of recovery codes
+func EnableTOTP(ctx context.Context, db *pgxpool.Pool, authID int, step int64, codeHashes []string) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE user_totp SET enabled_at = NOW(), last_step = $2
+		 WHERE authid = $1 AND enabled_at IS NULL`,
+		authID, step,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("no two-factor enrollment in progress")
+	}
+
+	if err := insertRecoveryCodes(ctx, tx, authID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ReplaceRecoveryCodes invalidates all earlier recovery codes
+func ReplaceRecoveryCodes(ctx context.Context, db *pgxpool.Pool, authID int, codeHashes []string) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertRecoveryCodes(ctx, tx, authID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+/*
+UseTOTPStep – records step as used. False means this or a later code was
+already accepted, i.e. the code is being replayed.
+*/
+func UseTOTPStep(ctx context.Context, db *pgxpool.Pool, authID int, step int64) (bool, error) {
+	tag, err := db.Exec(ctx,
+		`UPDATE user_totp SET last_step = $2
+		 WHERE authid = $1 AND enabled_at IS NOT NULL AND last_step < $2`,
+		authID, step,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// UseRecoveryCode burns a recovery code; false if it doesn't match an unused one
+func UseRecoveryCode(ctx context.Context, db *pgxpool.Pool, authID int, codeHash string) (bool, error) {
+	tag, err := db.Exec(ctx,
+		`UPDATE totp_recovery_codes SET used_at = NOW()
+		 WHERE authid = $1 AND codehash = $2 AND used_at IS NULL`,
+		authID, codeHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func CountRecoveryCodes(ctx context.Context, db *pgxpool.Pool, authID int) (int, error) {
+	var n int
+	err := db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM totp_recovery_codes WHERE authid = $1 AND used_at IS NULL`,
+		authID,
+	).Scan(&n)
+	return n, err
+}
+
+// DeleteTOTP removes the secret and recovery codes; false if 2FA was not set up
+func DeleteTOTP(ctx context.Context, tx pgx.Tx, authID int) (bool, error) {
+	if _, err := tx.Exec(ctx, `DELETE FROM totp_recovery_codes WHERE authid = $1`, authID); err != nil {
+		return false, err
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE authid = $1`, authID)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
@@ -6,19 +6,37 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// isUniqueViolation reports whether err came from a unique constraint or index
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
 type UserAuth struct {
 	AuthID       int
 	Email        string
 	PasswordHash string
 	Role         string
 	Username     string
+	VerifiedAt   *time.Time // email_verified_at, nil until the email is confirmed
+	LockedUntil  *time.Time // set after too many failed logins; failures before it no longer count
 	CustomerID   int
 	DeveloperID  int
 }
 
+type CustomerProfile struct {
+	CustomerID int
+	Username   string
+	FullName   *string
+	Email      string
+	Address    *string
+	Phone      *string
+}
+
 type UserDetail struct {
 	AuthID    int
 	Email     string
@@ -47,11 +65,12 @@ type AccountDetail struct {
 	DeveloperID   *int       // nil if not a developer
 	DeveloperName *string    // nil if not a developer
 	AuthDeleted   *time.Time // for devs linked auth deletion
+	LockedUntil   *time.Time
 }
 
 func GetUserAuthByEmail(ctx context.Context, db *pgxpool.Pool, email string) (*UserAuth, error) {
 	query := `
-        SELECT authid, email, passwordhash, role
+        SELECT authid, email, passwordhash, role, email_verified_at, locked_until
         FROM userauth
         WHERE email = $1
 			AND deleted_at IS NULL
@@ -61,7 +80,7 @@ func GetUserAuthByEmail(ctx context.Context, db *pgxpool.Pool, email string) (*U
 	row := db.QueryRow(ctx, query, email)
 
 	var ua UserAuth
-	err := row.Scan(&ua.AuthID, &ua.Email, &ua.PasswordHash, &ua.Role)
+	err := row.Scan(&ua.AuthID, &ua.Email, &ua.PasswordHash, &ua.Role, &ua.VerifiedAt, &ua.LockedUntil)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, errors.New("user not found")
@@ -100,6 +119,19 @@ func GetUserAuthByEmail(ctx context.Context, db *pgxpool.Pool, email string) (*U
 	return &ua, nil
 }
 
+// GetAuthIDByEmail finds an active account by email, verified or not
+func GetAuthIDByEmail(ctx context.Context, db *pgxpool.Pool, email string) (int, error) {
+	var authID int
+	err := db.QueryRow(ctx,
+		`SELECT authid FROM userauth WHERE lower(email) = lower($1) AND deleted_at IS NULL`,
+		email,
+	).Scan(&authID)
+	if err == pgx.ErrNoRows {
+		return 0, errors.New("user not found")
+	}
+	return authID, err
+}
+
 func GetCustomerInfoByAuthID(ctx context.Context, db *pgxpool.Pool, authID int) (string, int, error) {
 	var username string
 	var customerID int
@@ -118,6 +150,108 @@ func GetCustomerInfoByAuthID(ctx context.Context, db *pgxpool.Pool, authID int)
 	return username, customerID, nil
 }
 
+// FindCustomer looks up an active customer by email or username
+func FindCustomer(ctx context.Context, db *pgxpool.Pool, emailOrUsername string) (customerID, authID int, username string, err error) {
+	err = db.QueryRow(ctx,
+		`SELECT c.customerid, c.authid, COALESCE(c.username, '')
+		 FROM customers c
+		 JOIN userauth u ON u.authid = c.authid
+		 WHERE (lower(c.email) = lower($1) OR lower(c.username) = lower($1))
+		   AND c.deleted_at IS NULL
+		   AND u.deleted_at IS NULL
+		 LIMIT 1`,
+		emailOrUsername,
+	).Scan(&customerID, &authID, &username)
+	if err == pgx.ErrNoRows {
+		err = errors.New("no customer with that email or username")
+	}
+	return customerID, authID, username, err
+}
+
+func GetCustomerProfile(ctx context.Context, db *pgxpool.Pool, customerID int) (*CustomerProfile, error) {
+	var p CustomerProfile
+	err := db.QueryRow(ctx,
+		`SELECT customerid, COALESCE(username, ''), fullname, email, address, phone
+		 FROM customers
+		 WHERE customerid = $1`,
+		customerID,
+	).Scan(&p.CustomerID, &p.Username, &p.FullName, &p.Email, &p.Address, &p.Phone)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New("customer not found")
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UpdateCustomerProfile sets full name and phone; nil clears a field
+func UpdateCustomerProfile(ctx context.Context, db *pgxpool.Pool, customerID int, fullName, phone *string) error {
+	_, err := db.Exec(ctx,
+		`UPDATE customers SET fullname = $1, phone = $2 WHERE customerid = $3`,
+		fullName, phone, customerID,
+	)
+	return err
+}
+
+func UpdateUsername(ctx context.Context, db *pgxpool.Pool, customerID int, username string) error {
+	_, err := db.Exec(ctx,
+		`UPDATE customers SET username = $1 WHERE customerid = $2`,
+		username, customerID,
+	)
+	if isUniqueViolation(err) {
+		return errors.New("username is already taken")
+	}
+	return err
+}
+
+// EmailInUse checks every account, banned ones included, since emails stay unique
+func EmailInUse(ctx context.Context, db *pgxpool.Pool, email string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM userauth WHERE lower(email) = lower($1))`,
+		email,
+	).Scan(&exists)
+	return exists, err
+}
+
+func GetPasswordHash(ctx context.Context, db *pgxpool.Pool, authID int) (string, error) {
+	var hash string
+	err := db.QueryRow(ctx,
+		`SELECT passwordhash FROM userauth WHERE authid = $1 AND deleted_at IS NULL`,
+		authID,
+	).Scan(&hash)
+	if err == pgx.ErrNoRows {
+		return "", errors.New("user not found")
+	}
+	return hash, err
+}
+
+func UpdatePasswordHash(ctx context.Context, db *pgxpool.Pool, authID int, hash string) error {
+	_, err := db.Exec(ctx,
+		`UPDATE userauth SET passwordhash = $1 WHERE authid = $2`,
+		hash, authID,
+	)
+	return err
+}
+
+func GetCustomerAddress(ctx context.Context, db *pgxpool.Pool, customerID int) (*string, error) {
+	var address *string
+	err := db.QueryRow(ctx,
+		`SELECT address FROM customers WHERE customerid = $1`,
+		customerID,
+	).Scan(&address)
+	return address, err
+}
+
+func SetCustomerAddress(ctx context.Context, db *pgxpool.Pool, customerID int, address string) error {
+	_, err := db.Exec(ctx,
+		`UPDATE customers SET address = $1 WHERE customerid = $2`,
+		address, customerID,
+	)
+	return err
+}
+
 func RegisterUser(ctx context.Context, tx pgx.Tx, email, passwordHash, username string) error {
 
 	var authID int
@@ -137,6 +271,9 @@ func RegisterUser(ctx context.Context, tx pgx.Tx, email, passwordHash, username
         VALUES ($1, $2, $3);
     `
 	_, err = tx.Exec(ctx, queryCustomer, username, email, authID)
+	if isUniqueViolation(err) {
+		return errors.New("username is already taken")
+	}
 	if err != nil {
 		return err
 	}
@@ -209,31 +346,7 @@ func GetUserByID(ctx context.Context, db *pgxpool.Pool, authID int) (*UserDetail
 	return &u, nil
 }
 
-func SoftDeleteUser(ctx context.Context, db *pgxpool.Pool, authID int) error {
-	_, err := db.Exec(ctx,
-		`UPDATE userauth
-		 SET deleted_at = NOW()
-		 WHERE authid = $1
-		   AND role = 'user'
-		   AND deleted_at IS NULL`,
-		authID,
-	)
-	return err
-}
-
-func RestoreUser(ctx context.Context, db *pgxpool.Pool, authID int) error {
-	_, err := db.Exec(ctx,
-		`UPDATE userauth
-		 SET deleted_at = NULL
-		 WHERE authid = $1
-		   AND role = 'user'
-		   AND deleted_at IS NOT NULL`,
-		authID,
-	)
-	return err
-}
-
-func RegisterDeveloper(ctx context.Context, tx pgx.Tx, email, passwordHash, devName string) error {
+func RegisterDeveloper(ctx context.Context, tx pgx.Tx, email, passwordHash, devName string) (int, error) {
 	var authID int
 	queryUser := `
         INSERT INTO userauth (email, passwordhash, role)
@@ -241,7 +354,7 @@ func RegisterDeveloper(ctx context.Context, tx pgx.Tx, email, passwordHash, devN
         RETURNING authid;
     `
 	if err := tx.QueryRow(ctx, queryUser, email, passwordHash).Scan(&authID); err != nil {
-		return err
+		return 0, err
 	}
 
 	queryDev := `
@@ -249,10 +362,10 @@ func RegisterDeveloper(ctx context.Context, tx pgx.Tx, email, passwordHash, devN
         VALUES ($1, $2);
     `
 	if _, err := tx.Exec(ctx, queryDev, devName, authID); err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return authID, nil
 }
 
 func GetAllDevelopers(ctx context.Context, db *pgxpool.Pool) ([]DeveloperDetail, error) {
@@ -339,7 +452,7 @@ func GetDeveloperDetail(ctx context.Context, db *pgxpool.Pool, devID int) (*Deve
 func GetAllAccounts(ctx context.Context, db *pgxpool.Pool) ([]AccountDetail, error) {
 	query := `
         SELECT ua.authid, ua.email, ua.role, ua.created_at, ua.deleted_at,
-               d.developerid, d.developername, d.deleted_at AS auth_deleted
+               d.developerid, d.developername, d.deleted_at AS auth_deleted, ua.locked_until
         FROM userauth ua
         LEFT JOIN developers d ON ua.authid = d.authid
         ORDER BY ua.authid;
@@ -363,6 +476,7 @@ func GetAllAccounts(ctx context.Context, db *pgxpool.Pool) ([]AccountDetail, err
 			&a.DeveloperID,
 			&a.DeveloperName,
 			&a.AuthDeleted,
+			&a.LockedUntil,
 		); err != nil {
 			return nil, err
 		}
@@ -377,7 +491,7 @@ func GetAccountByAuthID(ctx context.Context, db *pgxpool.Pool, authID int) (*Acc
 
 	query := `
         SELECT ua.authid, ua.email, ua.role, ua.created_at, ua.deleted_at,
-               d.developerid, d.developername, d.deleted_at AS auth_deleted
+               d.developerid, d.developername, d.deleted_at AS auth_deleted, ua.locked_until
         FROM userauth ua
         LEFT JOIN developers d ON ua.authid = d.authid
         WHERE ua.authid = $1;
@@ -392,6 +506,7 @@ func GetAccountByAuthID(ctx context.Context, db *pgxpool.Pool, authID int) (*Acc
 		&a.DeveloperID,
 		&a.DeveloperName,
 		&a.AuthDeleted,
+		&a.LockedUntil,
 	)
 
 	if err != nil {
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WishlistItem struct {
+	GameID          int
+	Title           string
+	Price           float64
+	DiscountPercent int
+	Released        bool
+	AddedAt         time.Time
+}
+
+func AddToWishlist(ctx context.Context, db *pgxpool.Pool, customerID, gameID int) error {
+	_, err := db.Exec(ctx,
+		`INSERT INTO wishlists (customerid, gameid)
+		 VALUES ($1, $2)
+		 ON CONFLICT (customerid, gameid)
+		 DO UPDATE SET deleted_at = NULL, created_at = NOW()
+		 WHERE wishlists.deleted_at IS NOT NULL`,
+		customerID, gameID,
+	)
+	return err
+}
+
+func RemoveFromWishlist(ctx context.Context, db *pgxpool.Pool, customerID, gameID int) error {
+	_, err := db.Exec(ctx,
+		`UPDATE wishlists
+		 SET deleted_at = NOW()
+		 WHERE customerid = $1
+		   AND gameid = $2
+		   AND deleted_at IS NULL`,
+		customerID, gameID,
+	)
+	return err
+}
+
+func IsInWishlist(ctx context.Context, db *pgxpool.Pool, customerID, gameID int) (bool, error) {
+	var exists bool
+	err := db.QueryRow(ctx,
+		`SELECT EXISTS(
+			SELECT 1 FROM wishlists
+			WHERE customerid = $1 AND gameid = $2 AND deleted_at IS NULL
+		)`,
+		customerID, gameID,
+	).Scan(&exists)
+	return exists, err
+}
+
+func GetWishlist(ctx context.Context, db *pgxpool.Pool, customerID int) ([]WishlistItem, error) {
+	query := `
+        SELECT g.gameid, g.title, ` + effectivePriceSQL + `,
+               COALESCE(disc.percentoff, 0),
+               g.released_at IS NOT NULL,
+               w.created_at
+        FROM wishlists w
+        JOIN games g ON g.gameid = w.gameid` + activeDiscountJoin + `
+        WHERE w.customerid = $1
+          AND w.deleted_at IS NULL
+          AND g.deleted_at IS NULL
+          AND g.hidden_at IS NULL
+        ORDER BY w.created_at;
+    `
+
+	rows, err := db.Query(ctx, query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []WishlistItem{}
+	for rows.Next() {
+		var w WishlistItem
+		if err := rows.Scan(
+			&w.GameID,
+			&w.Title,
+			&w.Price,
+			&w.DiscountPercent,
+			&w.Released,
+			&w.AddedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, w)
+	}
+
+	return list, rows.Err()
+}
+
+type WishlistWatcher struct {
+	AuthID int
+	GameID int
+	Title  string
+}
+
+/*
+GetWishlistWatchers – customers wishlisting a game (or, when gameID is nil,
+any game of the developer) who don't own it yet
+*/
+func GetWishlistWatchers(ctx context.Context, db *pgxpool.Pool, gameID *int, devID int) ([]WishlistWatcher, error) {
+	query := `
+        SELECT c.authid, g.gameid, g.title
+        FROM wishlists w
+        JOIN customers c ON c.customerid = w.customerid
+        JOIN games g ON g.gameid = w.gameid
+        WHERE w.deleted_at IS NULL
+          AND g.deleted_at IS NULL
+          AND g.hidden_at IS NULL
+          AND g.developerid = $2
+          AND ($1::int IS NULL OR g.gameid = $1)
+          AND NOT EXISTS (
+              SELECT 1 FROM entitlements e
+              WHERE e.customerid = w.customerid
+                AND e.gameid = w.gameid
+                AND e.deleted_at IS NULL
+          );
+    `
+
+	rows, err := db.Query(ctx, query, gameID, devID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []WishlistWatcher
+	for rows.Next() {
+		var w WishlistWatcher
+		if err := rows.Scan(&w.AuthID, &w.GameID, &w.Title); err != nil {
+			return nil, err
+		}
+		list = append(list, w)
+	}
+
+	return list, rows.Err()
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	usernameRegex = regexp.MustCompile(`^[A-Za-z0-9_.\-]{3,20}$`)
+	phoneRegex    = regexp.MustCompile(`^\+?[0-9][0-9 ()\-]{5,18}$`)
+)
+
+func CustomerProfile(ctx context.Context, customerID int) (*repository.CustomerProfile, error) {
+	return repository.GetCustomerProfile(ctx, db.Pool, customerID)
+}
+
+// optional trims s and turns an empty value into nil so the column is cleared
+func optional(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func UpdateProfile(ctx context.Context, customerID int, fullName, phone string) error {
+	name, tel := optional(fullName), optional(phone)
+	if name != nil && len(*name) > 100 {
+		return errors.New("full name must be at most 100 characters")
+	}
+	if tel != nil && !phoneRegex.MatchString(*tel) {
+		return errors.New("phone may only contain digits, spaces, dashes, brackets and a leading +")
+	}
+
+	return repository.UpdateCustomerProfile(ctx, db.Pool, customerID, name, tel)
+}
+
+func ChangeUsername(ctx context.Context, customerID int, username string) error {
+	username = strings.TrimSpace(username)
+	if !usernameRegex.MatchString(username) {
+		return errors.New("username must be 3-20 letters, digits, dots, dashes or underscores")
+	}
+	return repository.UpdateUsername(ctx, db.Pool, customerID, username)
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// auditEntry is the log entry a change made under withAudit returns
+type auditEntry struct {
+	Action     string
+	TargetType string
+	TargetID   int // 0 if the target has no numeric ID
+	Before     any
+	After      any
+}
+
+/*
+withAudit runs change in a transaction and writes the entry it returns in the
+same one, so a privileged action never commits without its audit trail and a
+failed action leaves none.
+*/
+func withAudit(ctx context.Context, actorAuthID int, change func(tx pgx.Tx) (*auditEntry, error)) error {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	e, err := change(tx)
+	if err != nil {
+		return err
+	}
+	if err := repository.WriteAuditLog(ctx, tx, actorAuthID, e.Action, e.TargetType, e.TargetID, e.Before, e.After); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// audited runs change and logs it with the table row as it was before and after
+func audited(ctx context.Context, actorAuthID int, action, targetType, table string, id int, change func(tx pgx.Tx) error) error {
+	return withAudit(ctx, actorAuthID, func(tx pgx.Tx) (*auditEntry, error) {
+		before, err := repository.AuditSnapshot(ctx, tx, table, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := change(tx); err != nil {
+			return nil, err
+		}
+
+		after, err := repository.AuditSnapshot(ctx, tx, table, id)
+		if err != nil {
+			return nil, err
+		}
+		return &auditEntry{Action: action, TargetType: targetType, TargetID: id, Before: before, After: after}, nil
+	})
+}
+
+func SearchAuditLog(ctx context.Context, f repository.AuditFilter) ([]repository.AuditEntry, error) {
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return nil, fmt.Errorf("start date must be before end date")
+	}
+	return repository.SearchAuditLog(ctx, db.Pool, f)
+}
+
+type auditJSON struct {
+	AuditID     int64           `json:"audit_id"`
+	ActorAuthID *int            `json:"actor_auth_id"`
+	ActorEmail  *string         `json:"actor_email"`
+	Action      string          `json:"action"`
+	TargetType  *string         `json:"target_type"`
+	TargetID    *int            `json:"target_id"`
+	Before      json.RawMessage `json:"before"`
+	After       json.RawMessage `json:"after"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+func rawJSON(s *string) json.RawMessage {
+	if s == nil {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(*s)
+}
+
+func optionalText(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func optionalInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+// ExportAuditLog writes the matching entries to w as "csv" or "json"
+func ExportAuditLog(ctx context.Context, f repository.AuditFilter, w io.Writer, format string) error {
+	entries, err := SearchAuditLog(ctx, f)
+	if err != nil {
+		return err
+	}
+	return writeAuditEntries(w, entries, format)
+}
+
+// writeAuditEntries encodes entries; snapshots stay JSON objects in "json"
+// and JSON text in a "csv" cell
+func writeAuditEntries(w io.Writer, entries []repository.AuditEntry, format string) error {
+	switch format {
+	case "json":
+		records := make([]auditJSON, 0, len(entries))
+		for _, e := range entries {
+			records = append(records, auditJSON{
+				AuditID: e.AuditID, ActorAuthID: e.ActorAuthID, ActorEmail: e.ActorEmail,
+				Action: e.Action, TargetType: e.TargetType, TargetID: e.TargetID,
+				Before: rawJSON(e.Before), After: rawJSON(e.After), CreatedAt: e.CreatedAt,
+			})
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"audit_id", "created_at", "actor_auth_id", "actor_email", "action", "target_type", "target_id", "before", "after"}); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := cw.Write([]string{
+				strconv.FormatInt(e.AuditID, 10),
+				e.CreatedAt.Format(time.RFC3339),
+				optionalInt(e.ActorAuthID),
+				optionalText(e.ActorEmail),
+				e.Action,
+				optionalText(e.TargetType),
+				optionalInt(e.TargetID),
+				optionalText(e.Before),
+				optionalText(e.After),
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	return fmt.Errorf("unknown export format %q", format)
+}
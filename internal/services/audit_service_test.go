@@ -0,0 +1,91 @@
+package services
+
+import (
+	"GamesProject/internal/repository"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func auditFixtures() []repository.AuditEntry {
+	actor, target := 7, 42
+	email, targetType := "admin@example.com", "genre"
+	before, after := `{"name": "RPG"}`, `{"name": "Role-playing, \"classic\""}`
+	at := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	return []repository.AuditEntry{
+		{
+			AuditID: 1, ActorAuthID: &actor, ActorEmail: &email,
+			Action: "genre.rename", TargetType: &targetType, TargetID: &target,
+			Before: &before, After: &after, CreatedAt: at,
+		},
+		// written by the scheduler: no actor, and nothing before the change
+		{AuditID: 2, Action: "suspension.expire", After: &after, CreatedAt: at.Add(time.Minute)},
+	}
+}
+
+func TestWriteAuditEntriesCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeAuditEntries(&buf, auditFixtures(), "csv"); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not valid CSV: %v", err)
+	}
+
+	want := [][]string{
+		{"audit_id", "created_at", "actor_auth_id", "actor_email", "action", "target_type", "target_id", "before", "after"},
+		{"1", "2026-03-01T12:30:00Z", "7", "admin@example.com", "genre.rename", "genre", "42", `{"name": "RPG"}`, `{"name": "Role-playing, \"classic\""}`},
+		{"2", "2026-03-01T12:31:00Z", "", "", "suspension.expire", "", "", "", `{"name": "Role-playing, \"classic\""}`},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("csv rows:\n got %q\nwant %q", rows, want)
+	}
+}
+
+func TestWriteAuditEntriesJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeAuditEntries(&buf, auditFixtures(), "json"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("export is not valid JSON: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d records, want 2", len(got))
+	}
+
+	// snapshots are nested objects, not strings holding JSON
+	if before, ok := got[0]["before"].(map[string]any); !ok || before["name"] != "RPG" {
+		t.Errorf("before = %#v, want an object with name RPG", got[0]["before"])
+	}
+	if got[0]["actor_auth_id"] != float64(7) || got[0]["created_at"] != "2026-03-01T12:30:00Z" {
+		t.Errorf("record 1 = %v", got[0])
+	}
+	for _, key := range []string{"actor_auth_id", "actor_email", "target_type", "target_id", "before"} {
+		if v, ok := got[1][key]; !ok || v != nil {
+			t.Errorf("record 2 %s = %#v, want null", key, v)
+		}
+	}
+}
+
+func TestWriteAuditEntriesEmptyAndUnknown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeAuditEntries(&buf, nil, "json"); err != nil {
+		t.Fatal(err)
+	}
+	if got := bytes.TrimSpace(buf.Bytes()); string(got) != "[]" {
+		t.Errorf("empty json export = %s, want []", got)
+	}
+
+	if err := writeAuditEntries(&buf, nil, "xml"); err == nil {
+		t.Error("unknown format was accepted")
+	}
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+	"errors"
+)
+
+func AllBundles(ctx context.Context) ([]repository.Bundle, error) {
+	return repository.GetBundles(ctx, db.Pool, nil)
+}
+
+func DeveloperBundles(ctx context.Context, devID int) ([]repository.Bundle, error) {
+	return repository.GetBundles(ctx, db.Pool, &devID)
+}
+
+func GetBundle(ctx context.Context, bundleID int) (*repository.Bundle, error) {
+	return repository.GetBundle(ctx, db.Pool, bundleID)
+}
+
+func CreateBundle(ctx context.Context, devID int, title string, discountPercent int, gameIDs []int) (int, error) {
+	if title == "" {
+		return 0, errors.New("bundle title cannot be empty")
+	}
+	if discountPercent < 0 || discountPercent >= 100 {
+		return 0, errors.New("bundle discount must be between 0 and 99 percent")
+	}
+	return repository.CreateBundle(ctx, db.Pool, devID, title, discountPercent, gameIDs)
+}
+
+func RemoveBundle(ctx context.Context, devID, bundleID int) error {
+	return repository.RemoveBundle(ctx, db.Pool, devID, bundleID)
+}
+
+// SetBaseGame turns a game into a DLC of baseID, or back into a standalone game when nil
+func SetBaseGame(ctx context.Context, devID, gameID int, baseID *int) error {
+	return repository.SetBaseGame(ctx, db.Pool, devID, gameID, baseID)
+}
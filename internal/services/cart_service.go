@@ -4,16 +4,169 @@ import (
 	"GamesProject/internal/db"
 	"GamesProject/internal/repository"
 	"context"
+	"errors"
 	"fmt"
+	"math"
 )
 
-func AddToCart(ctx context.Context, customerID, gameID, qty int, price float64) error {
+// ErrCartChanged is returned by CheckoutCart when prices changed or games were
+// removed since they were added; see CheckCart and AcknowledgeCartChanges
+var ErrCartChanged = errors.New("your cart has changed since items were added")
+
+// CartIssue describes one cart line that no longer matches the store
+type CartIssue struct {
+	OrderItemID  int
+	Title        string
+	Removed      bool
+	OldPrice     float64
+	NewPrice     float64
+	NewListPrice float64
+}
+
+// checkQuantity enforces 1..maxPerOrder copies of a game per order
+func checkQuantity(title string, qty, maxPerOrder int) error {
+	if qty < 1 {
+		return errors.New("quantity must be at least 1")
+	}
+	if qty > maxPerOrder {
+		return fmt.Errorf("you can buy at most %d copies of %s per order", maxPerOrder, title)
+	}
+	return nil
+}
+
+// AddToCart adds a game at its current effective (discounted) price in the customer's currency
+func AddToCart(ctx context.Context, customerID, gameID, qty int) error {
+	cur, err := CustomerCurrency(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	price, listPrice, err := repository.GetLocalGamePrice(ctx, db.Pool, gameID, cur.Code, cur.ExchangeRate)
+	if err != nil {
+		return err
+	}
+
 	orderID, err := repository.GetActiveCart(ctx, db.Pool, customerID)
 	if err != nil {
 		return err
 	}
 
-	return repository.AddItemToCart(ctx, db.Pool, orderID, gameID, qty, price)
+	if err := checkBaseGame(ctx, customerID, orderID, gameID, nil); err != nil {
+		return err
+	}
+
+	limit, err := repository.GetGameMaxPerOrder(ctx, db.Pool, gameID)
+	if err != nil {
+		return err
+	}
+	inCart, err := repository.CartGameQuantity(ctx, db.Pool, orderID, gameID)
+	if err != nil {
+		return err
+	}
+	if qty < 1 {
+		return errors.New("quantity must be at least 1")
+	}
+	if inCart+qty > limit {
+		return fmt.Errorf("you can buy at most %d copies of this game per order (%d already in cart)", limit, inCart)
+	}
+
+	return repository.AddItemToCart(ctx, db.Pool, orderID, gameID, qty, price, listPrice, nil)
+}
+
+// AddBundleToCart expands a bundle into one cart line per game, priced with the bundle discount
+func AddBundleToCart(ctx context.Context, customerID, bundleID int) error {
+	bundle, err := repository.GetBundle(ctx, db.Pool, bundleID)
+	if err != nil {
+		return err
+	}
+
+	cur, err := CustomerCurrency(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	orderID, err := repository.GetActiveCart(ctx, db.Pool, customerID)
+	if err != nil {
+		return err
+	}
+
+	inBundle := map[int]bool{}
+	for _, item := range bundle.Items {
+		inBundle[item.GameID] = true
+	}
+
+	for _, item := range bundle.Items {
+		if err := checkBaseGame(ctx, customerID, orderID, item.GameID, inBundle); err != nil {
+			return err
+		}
+
+		limit, err := repository.GetGameMaxPerOrder(ctx, db.Pool, item.GameID)
+		if err != nil {
+			return err
+		}
+		inCart, err := repository.CartGameQuantity(ctx, db.Pool, orderID, item.GameID)
+		if err != nil {
+			return err
+		}
+		if err := checkQuantity(item.Title, inCart+1, limit); err != nil {
+			return err
+		}
+	}
+
+	var lines []repository.BundleLine
+	for _, item := range bundle.Items {
+		price, listPrice, err := repository.GetLocalGamePrice(ctx, db.Pool, item.GameID, cur.Code, cur.ExchangeRate)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, repository.BundleLine{
+			GameID:    item.GameID,
+			Price:     bundleLinePrice(price, bundle.DiscountPercent),
+			ListPrice: listPrice,
+		})
+	}
+
+	return repository.AddBundleLines(ctx, db.Pool, orderID, bundle.BundleID, lines)
+}
+
+// bundleLinePrice takes the bundle discount off a game's local price, so
+// bundled games honour the developer's regional prices like single ones
+func bundleLinePrice(localPrice float64, discountPercent int) float64 {
+	return roundMoney(localPrice * float64(100-discountPercent) / 100)
+}
+
+// checkBaseGame refuses a DLC unless its base game is already owned, in the cart,
+// or part of the same purchase (extra)
+func checkBaseGame(ctx context.Context, customerID, orderID, gameID int, extra map[int]bool) error {
+	baseID, err := repository.GetBaseGameID(ctx, db.Pool, gameID)
+	if err != nil {
+		return err
+	}
+	if baseID == nil || extra[*baseID] {
+		return nil
+	}
+
+	owned, err := repository.OwnsGame(ctx, db.Pool, customerID, *baseID)
+	if err != nil {
+		return err
+	}
+	if owned {
+		return nil
+	}
+
+	inCart, err := repository.CartHasGame(ctx, db.Pool, orderID, *baseID)
+	if err != nil {
+		return err
+	}
+	if inCart {
+		return nil
+	}
+
+	base, err := repository.GetGameDetails(ctx, db.Pool, *baseID)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("this DLC requires %s; buy it first or add it to your cart", base.Title)
 }
 
 func ViewCart(ctx context.Context, customerID int) (*repository.Cart, error) {
@@ -22,6 +175,10 @@ func ViewCart(ctx context.Context, customerID int) (*repository.Cart, error) {
 		return nil, err
 	}
 
+	if err := repository.MergeDuplicateCartLines(ctx, db.Pool, orderID); err != nil {
+		return nil, err
+	}
+
 	items, total, err := repository.GetCartItems(ctx, db.Pool, orderID)
 	if err != nil {
 		return nil, err
@@ -34,12 +191,118 @@ func ViewCart(ctx context.Context, customerID int) (*repository.Cart, error) {
 	}, nil
 }
 
-func UpdateQuantity(ctx context.Context, orderItemID, qty int) error {
-	return repository.UpdateCartItemQty(ctx, db.Pool, orderItemID, qty)
+// UpdateQuantity changes a line of the customer's cart, within the game's per-order limit
+func UpdateQuantity(ctx context.Context, customerID, orderItemID, qty int) error {
+	cart, err := ViewCart(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range cart.Items {
+		if item.OrderItemID != orderItemID {
+			continue
+		}
+		if item.BundleID != nil {
+			return errors.New("bundle items can't change quantity; remove the bundle instead")
+		}
+		if err := checkQuantity(item.Title, qty, item.MaxPerOrder); err != nil {
+			return err
+		}
+		return repository.UpdateCartItemQty(ctx, db.Pool, customerID, orderItemID, qty)
+	}
+
+	return repository.ErrCartItemNotFound
 }
 
-func RemoveFromCart(ctx context.Context, orderItemID int) error {
-	return repository.RemoveCartItem(ctx, db.Pool, orderItemID)
+// CheckCart compares every cart line with the store as it is now
+func CheckCart(ctx context.Context, customerID int) ([]CartIssue, error) {
+	cart, err := ViewCart(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return cartIssues(ctx, customerID, cart.Items)
+}
+
+func cartIssues(ctx context.Context, customerID int, items []repository.CartItem) ([]CartIssue, error) {
+	cur, err := CustomerCurrency(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	bundles := map[int]*repository.Bundle{}
+	issues := []CartIssue{}
+
+	for _, item := range items {
+		issue := CartIssue{OrderItemID: item.OrderItemID, Title: item.Title, OldPrice: item.PriceAtPurchase}
+
+		if item.Unavailable {
+			issue.Removed = true
+			issues = append(issues, issue)
+			continue
+		}
+
+		price, listPrice, err := repository.GetLocalGamePrice(ctx, db.Pool, item.GameID, cur.Code, cur.ExchangeRate)
+		if err != nil {
+			return nil, err
+		}
+
+		if item.BundleID != nil {
+			b, ok := bundles[*item.BundleID]
+			if !ok {
+				if b, err = repository.GetBundle(ctx, db.Pool, *item.BundleID); err != nil {
+					return nil, err
+				}
+				bundles[*item.BundleID] = b
+			}
+
+			inBundle := false
+			for _, bi := range b.Items {
+				if bi.GameID == item.GameID {
+					inBundle = true
+				}
+			}
+			if !inBundle {
+				issue.Removed = true
+				issues = append(issues, issue)
+				continue
+			}
+			price = bundleLinePrice(price, b.DiscountPercent)
+		}
+
+		if math.Abs(price-item.PriceAtPurchase) >= 0.005 {
+			issue.NewPrice = price
+			issue.NewListPrice = listPrice
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues, nil
+}
+
+// AcknowledgeCartChanges applies the changes found by CheckCart: lines get the
+// current price and removed games (with the rest of their bundle) leave the cart
+func AcknowledgeCartChanges(ctx context.Context, customerID int) error {
+	issues, err := CheckCart(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range issues {
+		if issue.Removed {
+			err = repository.RemoveCartItem(ctx, db.Pool, customerID, issue.OrderItemID)
+		} else {
+			err = repository.UpdateCartItemPrice(ctx, db.Pool, customerID, issue.OrderItemID, issue.NewPrice, issue.NewListPrice)
+		}
+		// an earlier removal may already have taken this line's bundle siblings
+		if err != nil && !errors.Is(err, repository.ErrCartItemNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func RemoveFromCart(ctx context.Context, customerID, orderItemID int) error {
+	return repository.RemoveCartItem(ctx, db.Pool, customerID, orderItemID)
 }
 
 func ClearCart(ctx context.Context, customerID int) error {
@@ -50,13 +313,26 @@ func ClearCart(ctx context.Context, customerID int) error {
 	return repository.ClearCart(ctx, db.Pool, orderID)
 }
 
-func CheckoutCart(ctx context.Context, customerID int) (int, float64, error) {
+// CheckoutCart turns the cart into a pending order. With a gift the games go to
+// the recipient once they accept; DLC then needs the recipient to own the base game.
+func CheckoutCart(ctx context.Context, customerID int, gift *GiftRequest) (int, float64, error) {
+	owner := customerID
+	var recipientID *int
+	var giftMessage *string
+	if gift != nil {
+		id, msg, err := resolveGift(ctx, customerID, *gift)
+		if err != nil {
+			return 0, 0, err
+		}
+		owner, recipientID, giftMessage = id, &id, msg
+	}
+
 	orderID, err := repository.GetActiveCart(ctx, db.Pool, customerID)
 	if err != nil {
 		return 0, 0, err
 	}
 
-	items, total, err := repository.GetCartItems(ctx, db.Pool, orderID)
+	items, _, err := repository.GetCartItems(ctx, db.Pool, orderID)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -65,10 +341,62 @@ func CheckoutCart(ctx context.Context, customerID int) (int, float64, error) {
 		return 0, 0, fmt.Errorf("cart is empty")
 	}
 
-	// finalize order by writing total price
-	if err := repository.Checkout(ctx, db.Pool, orderID, total); err != nil {
+	issues, err := cartIssues(ctx, customerID, items)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(issues) > 0 {
+		return 0, 0, ErrCartChanged
+	}
+
+	// base games may have been removed from the cart after their DLC was added
+	for _, item := range items {
+		if err := checkBaseGame(ctx, owner, orderID, item.GameID, nil); err != nil {
+			return 0, 0, fmt.Errorf("%s: %w", item.Title, err)
+		}
+	}
+
+	// the developer may have lowered the limit since the game was added
+	qty := map[int]int{}
+	for _, item := range items {
+		qty[item.GameID] += item.Quantity
+		if err := checkQuantity(item.Title, qty[item.GameID], item.MaxPerOrder); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	cur, err := CustomerCurrency(ctx, customerID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// the base currency is stored as NULL so it works without a row in the rate table
+	var code *string
+	if cur.Code != BaseCurrencyCode() {
+		code = &cur.Code
+	}
+
+	quote, err := QuoteTax(ctx, customerID, items)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// finalize order by writing total price and the rate used, so reports can convert back
+	err = repository.Checkout(ctx, db.Pool, orderID, repository.OrderTotals{
+		Total:        quote.Total,
+		CurrencyCode: code,
+		ExchangeRate: cur.ExchangeRate,
+		TaxRate:      quote.Rate,
+		TaxInclusive: quote.Inclusive,
+		TaxTotal:     quote.Tax,
+		ItemTax:      quote.ItemTax,
+
+		GiftRecipientID: recipientID,
+		GiftMessage:     giftMessage,
+	})
+	if err != nil {
 		return 0, 0, err
 	}
 
-	return orderID, total, nil
+	return orderID, quote.Total, nil
 }
@@ -0,0 +1,42 @@
+package services
+
+import "testing"
+
+func TestBundleLinePrice(t *testing.T) {
+	tests := []struct {
+		local    float64
+		discount int
+		want     float64
+	}{
+		{10, 0, 10},
+		{10, 25, 7.5},
+		{9.99, 20, 7.99},
+		{49.90, 15, 42.42}, // regional price, not the converted base price
+		{5, 100, 0},
+	}
+
+	for _, tt := range tests {
+		if got := bundleLinePrice(tt.local, tt.discount); got != tt.want {
+			t.Errorf("bundleLinePrice(%v, %d) = %v, want %v", tt.local, tt.discount, got, tt.want)
+		}
+	}
+}
+
+func TestCheckQuantity(t *testing.T) {
+	tests := []struct {
+		qty, max int
+		ok       bool
+	}{
+		{1, 10, true},
+		{10, 10, true},
+		{11, 10, false},
+		{0, 10, false},
+		{-1, 10, false},
+	}
+
+	for _, tt := range tests {
+		if err := checkQuantity("Game", tt.qty, tt.max); (err == nil) != tt.ok {
+			t.Errorf("checkQuantity(%d, %d) = %v, want ok %v", tt.qty, tt.max, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CatalogRow is one game in an import/export file
+type CatalogRow struct {
+	Row         int // 1-based data row (CSV header excluded)
+	SKU         string
+	Title       string
+	Price       float64
+	ReleaseDate *time.Time
+	Genres      []string
+}
+
+type CatalogRowError struct {
+	Row int
+	SKU string
+	Err string
+}
+
+type CatalogImportResult struct {
+	Created int
+	Updated int
+	Errors  []CatalogRowError
+}
+
+// catalogJSON is the on-disk JSON shape of a CatalogRow
+type catalogJSON struct {
+	SKU         string   `json:"sku"`
+	Title       string   `json:"title"`
+	Price       float64  `json:"price"`
+	ReleaseDate string   `json:"release_date"`
+	Genres      []string `json:"genres"`
+}
+
+var catalogCSVHeader = []string{"sku", "title", "price", "release_date", "genres"}
+
+// CatalogFormat picks "csv" or "json" from a file extension
+func CatalogFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return "csv", nil
+	case ".json":
+		return "json", nil
+	}
+	return "", fmt.Errorf("unsupported file type %q (use .csv or .json)", filepath.Ext(path))
+}
+
+// ParseCatalogFile reads a CSV or JSON catalog. Rows that cannot be parsed are
+// returned as row errors instead of failing the whole file.
+func ParseCatalogFile(path string) ([]CatalogRow, []CatalogRowError, error) {
+	format, err := CatalogFormat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	if format == "json" {
+		return parseCatalogJSON(f)
+	}
+	return parseCatalogCSV(f)
+}
+
+func parseCatalogCSV(r io.Reader) ([]CatalogRow, []CatalogRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(catalogCSVHeader)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading header: %w", err)
+	}
+	for i, col := range catalogCSVHeader {
+		if strings.ToLower(strings.TrimSpace(header[i])) != col {
+			return nil, nil, fmt.Errorf("header must be: %s", strings.Join(catalogCSVHeader, ","))
+		}
+	}
+
+	var rows []CatalogRow
+	var rowErrs []CatalogRowError
+
+	for n := 1; ; n++ {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rowErrs = append(rowErrs, CatalogRowError{Row: n, Err: err.Error()})
+			continue
+		}
+
+		row := CatalogRow{
+			Row:   n,
+			SKU:   strings.TrimSpace(rec[0]),
+			Title: strings.TrimSpace(rec[1]),
+		}
+
+		row.Price, err = strconv.ParseFloat(strings.TrimSpace(rec[2]), 64)
+		if err != nil {
+			rowErrs = append(rowErrs, CatalogRowError{Row: n, SKU: row.SKU, Err: "invalid price"})
+			continue
+		}
+
+		row.ReleaseDate, err = parseCatalogDate(rec[3])
+		if err != nil {
+			rowErrs = append(rowErrs, CatalogRowError{Row: n, SKU: row.SKU, Err: err.Error()})
+			continue
+		}
+
+		for _, g := range strings.Split(rec[4], "|") {
+			if g = strings.TrimSpace(g); g != "" {
+				row.Genres = append(row.Genres, g)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrs, nil
+}
+
+func parseCatalogJSON(r io.Reader) ([]CatalogRow, []CatalogRowError, error) {
+	var records []catalogJSON
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var rows []CatalogRow
+	var rowErrs []CatalogRowError
+
+	for i, rec := range records {
+		row := CatalogRow{
+			Row:    i + 1,
+			SKU:    strings.TrimSpace(rec.SKU),
+			Title:  strings.TrimSpace(rec.Title),
+			Price:  rec.Price,
+			Genres: rec.Genres,
+		}
+
+		date, err := parseCatalogDate(rec.ReleaseDate)
+		if err != nil {
+			rowErrs = append(rowErrs, CatalogRowError{Row: row.Row, SKU: row.SKU, Err: err.Error()})
+			continue
+		}
+		row.ReleaseDate = date
+
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrs, nil
+}
+
+func parseCatalogDate(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, errors.New("invalid release_date, use YYYY-MM-DD")
+	}
+	return &t, nil
+}
+
+/*
+ImportCatalog validates every row first and writes nothing if any row is invalid.
+Valid files are upserted by SKU in one transaction, so a row that fails to save
+rolls back the whole file and re-running an import is safe. With dryRun
+the result only reports what would be created or updated.
+*/
+func ImportCatalog(ctx context.Context, devID int, rows []CatalogRow, dryRun bool) (*CatalogImportResult, error) {
+	if !DeveloperExists(ctx, devID) {
+		return nil, errors.New("developer not found")
+	}
+
+	genres, err := repository.GetAllGenre(ctx, db.Pool)
+	if err != nil {
+		return nil, err
+	}
+	genreIDs := map[string]int{}
+	for _, g := range genres {
+		genreIDs[strings.ToLower(g.GenreName)] = g.GenreID
+	}
+
+	result := &CatalogImportResult{}
+	seen := map[string]int{}
+
+	for _, row := range rows {
+		fail := func(msg string) {
+			result.Errors = append(result.Errors, CatalogRowError{Row: row.Row, SKU: row.SKU, Err: msg})
+		}
+
+		switch {
+		case row.SKU == "":
+			fail("sku is required")
+		case len(row.SKU) > 64:
+			fail("sku is longer than 64 characters")
+		case row.Title == "":
+			fail("title is required")
+		case len(row.Title) > 200:
+			fail("title is longer than 200 characters")
+		case row.Price < 0:
+			fail("price cannot be negative")
+		}
+
+		if first, dup := seen[row.SKU]; dup && row.SKU != "" {
+			fail(fmt.Sprintf("duplicate sku, first used on row %d", first))
+		}
+		seen[row.SKU] = row.Row
+
+		for _, g := range row.Genres {
+			if _, ok := genreIDs[strings.ToLower(g)]; !ok {
+				fail(fmt.Sprintf("unknown genre %q", g))
+			}
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	if dryRun {
+		for _, row := range rows {
+			existing, err := repository.GetGameIDBySKU(ctx, db.Pool, devID, row.SKU)
+			if err != nil {
+				return nil, err
+			}
+			if existing == nil {
+				result.Created++
+			} else {
+				result.Updated++
+			}
+		}
+		return result, nil
+	}
+
+	// the whole file goes in or none of it does
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, row := range rows {
+		fail := func(err error) (*CatalogImportResult, error) {
+			return &CatalogImportResult{
+				Errors: []CatalogRowError{{Row: row.Row, SKU: row.SKU, Err: err.Error()}},
+			}, nil
+		}
+
+		gameID, created, err := repository.UpsertGameBySKU(ctx, tx, devID, row.SKU, row.Title, row.Price, row.ReleaseDate)
+		if err != nil {
+			return fail(err)
+		}
+
+		var ids []int
+		for _, g := range row.Genres {
+			ids = append(ids, genreIDs[strings.ToLower(g)])
+		}
+		if err := repository.SetGameGenres(ctx, tx, gameID, ids); err != nil {
+			return fail(err)
+		}
+
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ExportCatalog writes the developer's catalog as "csv" or "json"
+func ExportCatalog(ctx context.Context, devID int, w io.Writer, format string) error {
+	if !DeveloperExists(ctx, devID) {
+		return errors.New("developer not found")
+	}
+
+	games, err := repository.GetDeveloperCatalog(ctx, db.Pool, devID)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		records := []catalogJSON{}
+		for _, g := range games {
+			rec := catalogJSON{SKU: g.SKU, Title: g.Title, Price: g.Price, Genres: g.Genres}
+			if g.ReleaseDate != nil {
+				rec.ReleaseDate = g.ReleaseDate.Format("2006-01-02")
+			}
+			if rec.Genres == nil {
+				rec.Genres = []string{}
+			}
+			records = append(records, rec)
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(catalogCSVHeader); err != nil {
+			return err
+		}
+		for _, g := range games {
+			release := ""
+			if g.ReleaseDate != nil {
+				release = g.ReleaseDate.Format("2006-01-02")
+			}
+			if err := cw.Write([]string{
+				g.SKU,
+				g.Title,
+				strconv.FormatFloat(g.Price, 'f', 2, 64),
+				release,
+				strings.Join(g.Genres, "|"),
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	return fmt.Errorf("unsupported format %q (use csv or json)", format)
+}
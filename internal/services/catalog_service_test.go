@@ -0,0 +1,84 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCatalogCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		wantSKUs []string
+		wantErrs []int // rows reported as errors
+		fatal    bool
+	}{
+		{
+			name:     "valid rows",
+			in:       "sku,title,price,release_date,genres\nA1,Alpha,9.99,2026-01-02,RPG|Indie\nB2,Beta,0,,\n",
+			wantSKUs: []string{"A1", "B2"},
+		},
+		{
+			name:     "bad price and date are row errors",
+			in:       "sku,title,price,release_date,genres\nA1,Alpha,x,,\nB2,Beta,1,02/01/2026,\nC3,Gamma,1,,\n",
+			wantSKUs: []string{"C3"},
+			wantErrs: []int{1, 2},
+		},
+		{
+			name:     "quoted title with comma",
+			in:       "sku,title,price,release_date,genres\n\"A1\",\"Alpha, Deluxe\",1,,\n",
+			wantSKUs: []string{"A1"},
+		},
+		{
+			name:  "wrong header",
+			in:    "id,title,price,release_date,genres\n",
+			fatal: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, rowErrs, err := parseCatalogCSV(strings.NewReader(tt.in))
+			if tt.fatal {
+				if err == nil {
+					t.Fatal("want an error for the file")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCatalogCSV: %v", err)
+			}
+
+			var skus []string
+			for _, r := range rows {
+				skus = append(skus, r.SKU)
+			}
+			if !reflect.DeepEqual(skus, tt.wantSKUs) {
+				t.Errorf("skus = %v, want %v", skus, tt.wantSKUs)
+			}
+
+			var errRows []int
+			for _, e := range rowErrs {
+				errRows = append(errRows, e.Row)
+			}
+			if !reflect.DeepEqual(errRows, tt.wantErrs) {
+				t.Errorf("error rows = %v, want %v", errRows, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestParseCatalogCSVGenres(t *testing.T) {
+	rows, _, err := parseCatalogCSV(strings.NewReader(
+		"sku,title,price,release_date,genres\nA1,Alpha,1,2026-03-04, RPG | |Indie \n"))
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("parseCatalogCSV = %v, %v", rows, err)
+	}
+	if want := []string{"RPG", "Indie"}; !reflect.DeepEqual(rows[0].Genres, want) {
+		t.Errorf("genres = %v, want %v", rows[0].Genres, want)
+	}
+	if got := rows[0].ReleaseDate.Format("2006-01-02"); got != "2026-03-04" {
+		t.Errorf("release date = %s", got)
+	}
+}
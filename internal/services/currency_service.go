@@ -0,0 +1,157 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var currencyCodeRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// BaseCurrencyCode is the currency game prices are entered in and reports are
+// converted to. Set BASE_CURRENCY to change it (default USD).
+func BaseCurrencyCode() string {
+	if code := strings.ToUpper(strings.TrimSpace(os.Getenv("BASE_CURRENCY"))); code != "" {
+		return code
+	}
+	return "USD"
+}
+
+// BaseCurrency falls back to "$" when the base currency has no row in the rate table
+func BaseCurrency(ctx context.Context) repository.Currency {
+	c, err := repository.GetCurrency(ctx, db.Pool, BaseCurrencyCode())
+	if err != nil {
+		return repository.Currency{Code: BaseCurrencyCode(), Symbol: "$", ExchangeRate: 1}
+	}
+	c.ExchangeRate = 1
+	return *c
+}
+
+// CustomerCurrency returns the currency chosen in the customer's profile, or the base currency
+func CustomerCurrency(ctx context.Context, customerID int) (repository.Currency, error) {
+	code, err := repository.GetCustomerCurrencyCode(ctx, db.Pool, customerID)
+	if err != nil {
+		return repository.Currency{}, err
+	}
+	if code == nil || *code == BaseCurrencyCode() {
+		return BaseCurrency(ctx), nil
+	}
+
+	c, err := repository.GetCurrency(ctx, db.Pool, *code)
+	if err != nil {
+		return repository.Currency{}, err
+	}
+	return *c, nil
+}
+
+func FormatMoney(c repository.Currency, amount float64) string {
+	return fmt.Sprintf("%s%.2f", c.Symbol, amount)
+}
+
+func ListCurrencies(ctx context.Context) ([]repository.Currency, error) {
+	return repository.GetCurrencies(ctx, db.Pool)
+}
+
+// SetCustomerCurrency is refused while the cart has items, since cart lines are priced in the old currency
+func SetCustomerCurrency(ctx context.Context, customerID int, code string) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	// only looks the cart up: changing currency must not open one
+	cartID, err := repository.FindActiveCart(ctx, db.Pool, customerID)
+	if err != nil {
+		return err
+	}
+	if cartID != nil {
+		items, _, err := repository.GetCartItems(ctx, db.Pool, *cartID)
+		if err != nil {
+			return err
+		}
+		if len(items) > 0 {
+			return errors.New("empty your cart before changing currency")
+		}
+	}
+
+	if code == BaseCurrencyCode() {
+		return repository.SetCustomerCurrency(ctx, db.Pool, customerID, nil)
+	}
+	if _, err := repository.GetCurrency(ctx, db.Pool, code); err != nil {
+		return err
+	}
+	return repository.SetCustomerCurrency(ctx, db.Pool, customerID, &code)
+}
+
+// SaveCurrency adds or updates an exchange rate. The base currency is always 1.
+func SaveCurrency(ctx context.Context, actorAuthID int, code, symbol string, rate float64) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	symbol = strings.TrimSpace(symbol)
+
+	if !currencyCodeRegex.MatchString(code) {
+		return errors.New("currency code must be 3 letters, e.g. EUR")
+	}
+	if symbol == "" || len(symbol) > 5 {
+		return errors.New("symbol must be 1-5 characters")
+	}
+	if code == BaseCurrencyCode() {
+		rate = 1
+	}
+	if rate <= 0 {
+		return errors.New("exchange rate must be positive")
+	}
+
+	return withAudit(ctx, actorAuthID, func(tx pgx.Tx) (*auditEntry, error) {
+		before, err := repository.AuditSnapshot(ctx, tx, "currencies", code)
+		if err != nil {
+			return nil, err
+		}
+		if err := repository.UpsertCurrency(ctx, tx, code, symbol, rate); err != nil {
+			return nil, err
+		}
+		after, err := repository.AuditSnapshot(ctx, tx, "currencies", code)
+		if err != nil {
+			return nil, err
+		}
+		// currencies are keyed by code, which the snapshots carry
+		return &auditEntry{Action: "currency.save", TargetType: "currency", Before: before, After: after}, nil
+	})
+}
+
+// LocalGamePrice is what the customer pays for a game in their currency
+func LocalGamePrice(ctx context.Context, customerID, gameID int) (float64, repository.Currency, error) {
+	cur, err := CustomerCurrency(ctx, customerID)
+	if err != nil {
+		return 0, cur, err
+	}
+
+	price, _, err := repository.GetLocalGamePrice(ctx, db.Pool, gameID, cur.Code, cur.ExchangeRate)
+	return price, cur, err
+}
+
+func RegionalPrices(ctx context.Context, gameID int) ([]repository.RegionalPrice, error) {
+	return repository.GetRegionalPrices(ctx, db.Pool, gameID)
+}
+
+// SetRegionalPrice overrides the converted default price of a game in one currency
+func SetRegionalPrice(ctx context.Context, devID, gameID int, code string, price float64) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == BaseCurrencyCode() {
+		return errors.New("edit the game to change its base price")
+	}
+	if price < 0 {
+		return errors.New("price cannot be negative")
+	}
+	if _, err := repository.GetCurrency(ctx, db.Pool, code); err != nil {
+		return err
+	}
+	return repository.SetRegionalPrice(ctx, db.Pool, devID, gameID, code, price)
+}
+
+func RemoveRegionalPrice(ctx context.Context, devID, gameID int, code string) error {
+	return repository.RemoveRegionalPrice(ctx, db.Pool, devID, gameID, strings.ToUpper(strings.TrimSpace(code)))
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ScheduleDiscount creates a time-boxed discount for one game,
+// or for the developer's whole catalog when gameID is nil
+func ScheduleDiscount(ctx context.Context, devID int, gameID *int, percentOff int, startsAt, endsAt time.Time) (int, error) {
+	if percentOff <= 0 || percentOff > 100 {
+		return 0, errors.New("discount must be between 1 and 100 percent")
+	}
+	if !endsAt.After(startsAt) {
+		return 0, errors.New("discount must end after it starts")
+	}
+	if endsAt.Before(time.Now()) {
+		return 0, errors.New("discount would already be over")
+	}
+
+	return repository.CreateDiscount(ctx, db.Pool, devID, gameID, percentOff, startsAt, endsAt)
+}
+
+func DeveloperDiscounts(ctx context.Context, devID int) ([]repository.Discount, error) {
+	return repository.GetDeveloperDiscounts(ctx, db.Pool, devID)
+}
+
+func CancelDiscount(ctx context.Context, devID, discountID int) error {
+	return repository.CancelDiscount(ctx, db.Pool, devID, discountID)
+}
+
+// SyncDiscountPriceHistory records a price history entry for every game whose
+// price changed because a discount started, ended or was cancelled, and tells
+// wishlisting customers about new sales
+func SyncDiscountPriceHistory(ctx context.Context) error {
+	// each batch is activated and recorded in one transaction
+	started, err := repository.ActivateDueDiscounts(ctx, db.Pool)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, d := range started {
+		if err := notifyWishlists(ctx, d.GameID, d.DeveloperID, saleMessage(d)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if _, err := repository.ConcludeEndedDiscounts(ctx, db.Pool); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func saleMessage(d repository.Discount) func(string) string {
+	return func(title string) string {
+		return fmt.Sprintf("%s from your wishlist is on sale: -%d%% until %s!",
+			title, d.PercentOff, d.EndsAt.Format("2006-01-02 15:04"))
+	}
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"GamesProject/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestSaleMessage(t *testing.T) {
+	ends := time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		title   string
+		percent int
+		want    string
+	}{
+		{"Hades", 20, "Hades from your wishlist is on sale: -20% until 2026-10-20 10:00!"},
+		{"100% Orange Juice", 50, "100% Orange Juice from your wishlist is on sale: -50% until 2026-10-20 10:00!"},
+		{"%s %d", 5, "%s %d from your wishlist is on sale: -5% until 2026-10-20 10:00!"},
+	}
+
+	for _, tt := range tests {
+		msg := saleMessage(repository.Discount{PercentOff: tt.percent, EndsAt: ends})
+		if got := msg(tt.title); got != tt.want {
+			t.Errorf("saleMessage(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"GamesProject/internal/db"
 	"GamesProject/internal/repository"
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+
+	"github.com/jackc/pgx/v5"
 )
 
 func AllGames(ctx context.Context, page int) ([]repository.GameList, int, error) {
@@ -38,6 +41,67 @@ func AllGames(ctx context.Context, page int) ([]repository.GameList, int, error)
 	return allGames[start:end], totalPages, nil
 }
 
+func ComingSoonGames(ctx context.Context, page int) ([]repository.GameList, int, error) {
+	const pageSize = 10
+
+	games, err := repository.GetComingSoonGames(ctx, db.Pool)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(games)
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+	if page < 1 {
+		page = 1
+	}
+	if page > totalPages {
+		page = totalPages
+	}
+
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	return games[start:end], totalPages, nil
+}
+
+// ReleaseDueGames flips games whose release date has arrived to released,
+// unlocks their pre-orders and notifies the buyers and wishlisting customers.
+// A game that fails to release is retried on the next run; notifications
+// for the others still go out.
+func ReleaseDueGames(ctx context.Context) error {
+	released, err := repository.ReleaseDueGames(ctx, db.Pool)
+
+	var errs []error
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, g := range released {
+		msg := fmt.Sprintf("%s is out now! Your pre-order has been unlocked in your library.", g.Title)
+		for _, authID := range g.UnlockedAuthIDs {
+			if err := repository.CreateNotification(ctx, db.Pool, authID, msg); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		gameID := g.GameID
+		if err := notifyWishlists(ctx, &gameID, g.DeveloperID, func(title string) string {
+			return title + " from your wishlist has been released!"
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 func GameDetails(id int) {
 	ctx := context.Background()
 
@@ -48,10 +112,45 @@ func GameDetails(id int) {
 	}
 
 	fmt.Println("Title:", details.Title)
-	fmt.Println("Price:", details.Price)
+	if details.DiscountPercent > 0 {
+		fmt.Printf("Price: %.2f (was %.2f, -%d%%", details.Price, details.BasePrice, details.DiscountPercent)
+		if details.DiscountEndsAt != nil {
+			fmt.Printf(" until %s", details.DiscountEndsAt.Format("2006-01-02 15:04"))
+		}
+		fmt.Println(")")
+	} else {
+		fmt.Println("Price:", details.Price)
+	}
+	if details.LowestPrice30d != nil {
+		if details.DiscountPercent > 0 {
+			fmt.Printf("Lowest price in the 30 days before this sale: %.2f\n", *details.LowestPrice30d)
+		} else {
+			fmt.Printf("Lowest price in last 30 days: %.2f\n", *details.LowestPrice30d)
+		}
+	}
 	fmt.Println("Developer:", details.DeveloperName)
+	if details.BaseGameTitle != nil {
+		fmt.Println("DLC for:", *details.BaseGameTitle)
+	}
 	fmt.Println("Genres:", strings.Join(details.Genres, ", "))
-	fmt.Printf("Year: %s\n", details.ReleaseDate.Format("2006-01-02"))
+	if len(details.TopTags) > 0 {
+		var tags []string
+		for _, t := range details.TopTags {
+			tags = append(tags, fmt.Sprintf("%s (%d)", t.TagName, t.Votes))
+		}
+		fmt.Println("Tags:", strings.Join(tags, ", "))
+	}
+	if details.RatingCount > 0 {
+		fmt.Printf("Rating: %.1f / 5 (%d reviews)\n", details.AverageRating, details.RatingCount)
+	} else {
+		fmt.Println("Rating: no reviews yet")
+	}
+	if details.ReleaseDate != nil {
+		fmt.Printf("Year: %s\n", details.ReleaseDate.Format("2006-01-02"))
+	}
+	if !details.Released {
+		fmt.Println("Status: Coming soon (pre-order available)")
+	}
 
 }
 
@@ -59,12 +158,18 @@ func GamePrice(ctx context.Context, gameID int) (float64, error) {
 	return repository.GetGamePrice(ctx, db.Pool, gameID)
 }
 
+func GamePriceHistory(ctx context.Context, gameID int) ([]repository.PricePoint, error) {
+	return repository.GetPriceHistory(ctx, db.Pool, gameID)
+}
+
 func AddGame(ctx context.Context, title string, price float64, releaseDate string, developerID int) (int, error) {
 	return repository.AddGame(ctx, db.Pool, title, price, releaseDate, developerID)
 }
 
-func RemoveGame(ctx context.Context, gameID int, role string, devID int) error {
-	return repository.RemoveGame(ctx, db.Pool, gameID, role, devID)
+func RemoveGame(ctx context.Context, actorAuthID, gameID int, role string, devID int) error {
+	return audited(ctx, actorAuthID, "game.remove", "game", "games", gameID, func(tx pgx.Tx) error {
+		return repository.RemoveGame(ctx, tx, gameID, role, devID)
+	})
 }
 
 func EditGameDetails(ctx context.Context, id int, title string, price float64, releaseDate string, devID int) error {
@@ -87,3 +192,10 @@ func AddGenreToGame(ctx context.Context, gameID, genreID int) error {
 func UpdateGameGenres(ctx context.Context, gameID int, genreIDs []int) error {
 	return repository.UpdateGameGenres(ctx, db.Pool, gameID, genreIDs)
 }
+
+func SetGameMaxPerOrder(ctx context.Context, devID, gameID, limit int) error {
+	if limit < 1 {
+		return errors.New("limit must be at least 1")
+	}
+	return repository.SetGameMaxPerOrder(ctx, db.Pool, devID, gameID, limit)
+}
@@ -4,6 +4,10 @@ import (
 	"GamesProject/internal/db"
 	"GamesProject/internal/repository"
 	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
 )
 
 func AllGenres(ctx context.Context, page int) ([]repository.GenreList, int, error) {
@@ -36,10 +40,143 @@ func AllGenres(ctx context.Context, page int) ([]repository.GenreList, int, erro
 	return allGenres[start:end], totalPages, nil
 }
 
-func AddGenre(ctx context.Context, name string) error {
-	return repository.AddGenre(ctx, db.Pool, name)
+// GenreNode is a genre with its depth in the hierarchy, for tree display
+type GenreNode struct {
+	repository.GenreList
+	Depth int
+}
+
+// GenreTree returns live genres ordered depth-first (parents before their sub-genres)
+func GenreTree(ctx context.Context) ([]GenreNode, error) {
+	genres, err := repository.GetAllGenre(ctx, db.Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	live := map[int]bool{}
+	for _, g := range genres {
+		live[g.GenreID] = true
+	}
+
+	children := map[int][]repository.GenreList{}
+	var roots []repository.GenreList
+	for _, g := range genres {
+		// sub-genres of a deleted parent are shown at the top level
+		if g.ParentID == nil || !live[*g.ParentID] {
+			roots = append(roots, g)
+			continue
+		}
+		children[*g.ParentID] = append(children[*g.ParentID], g)
+	}
+
+	var out []GenreNode
+	var walk func(list []repository.GenreList, depth int)
+	walk = func(list []repository.GenreList, depth int) {
+		for _, g := range list {
+			out = append(out, GenreNode{GenreList: g, Depth: depth})
+			walk(children[g.GenreID], depth+1)
+		}
+	}
+	walk(roots, 0)
+
+	return out, nil
+}
+
+func AddGenre(ctx context.Context, name string, parentID *int) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("genre name cannot be empty")
+	}
+	return repository.AddGenre(ctx, db.Pool, name, parentID)
+}
+
+func RemoveGenre(ctx context.Context, actorAuthID, genreID int) error {
+	return audited(ctx, actorAuthID, "genre.remove", "genre", "genres", genreID, func(tx pgx.Tx) error {
+		return repository.RemoveGenre(ctx, tx, genreID)
+	})
+}
+
+func RenameGenre(ctx context.Context, genreID int, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("genre name cannot be empty")
+	}
+	return repository.RenameGenre(ctx, db.Pool, genreID, name)
+}
+
+func DeletedGenres(ctx context.Context) ([]repository.GenreList, error) {
+	return repository.GetDeletedGenres(ctx, db.Pool)
+}
+
+func RestoreGenre(ctx context.Context, genreID int) error {
+	return repository.RestoreGenre(ctx, db.Pool, genreID)
 }
 
-func RemoveGenre(ctx context.Context, genreID int) error {
-	return repository.RemoveGenre(ctx, db.Pool, genreID)
+// MergeGenres is audited against the merged genre, with both genres before and after
+func MergeGenres(ctx context.Context, actorAuthID, fromID, intoID int) error {
+	return withAudit(ctx, actorAuthID, func(tx pgx.Tx) (*auditEntry, error) {
+		snapshot := func() (map[string]any, error) {
+			from, err := repository.AuditSnapshot(ctx, tx, "genres", fromID)
+			if err != nil {
+				return nil, err
+			}
+			into, err := repository.AuditSnapshot(ctx, tx, "genres", intoID)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"from": from, "into": into}, nil
+		}
+
+		before, err := snapshot()
+		if err != nil {
+			return nil, err
+		}
+		if err := repository.MergeGenres(ctx, tx, fromID, intoID); err != nil {
+			return nil, err
+		}
+		after, err := snapshot()
+		if err != nil {
+			return nil, err
+		}
+		return &auditEntry{Action: "genre.merge", TargetType: "genre", TargetID: fromID, Before: before, After: after}, nil
+	})
+}
+
+func SetGenreParent(ctx context.Context, genreID int, parentID *int) error {
+	return repository.SetGenreParent(ctx, db.Pool, genreID, parentID)
+}
+
+func GenreUsageCount(ctx context.Context, genreID int) (int, error) {
+	return repository.GenreUsageCount(ctx, db.Pool, genreID)
+}
+
+// GamesByGenre pages through games in the genre, sub-genres included
+func GamesByGenre(ctx context.Context, genreID, page int) ([]repository.GameList, int, error) {
+	const pageSize = 10
+
+	games, err := repository.GetGamesByGenre(ctx, db.Pool, genreID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(games)
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+	if page < 1 {
+		page = 1
+	}
+	if page > totalPages {
+		page = totalPages
+	}
+
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	return games[start:end], totalPages, nil
 }
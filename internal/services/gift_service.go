@@ -0,0 +1,70 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GiftRequest names the recipient of a checkout by email or username
+type GiftRequest struct {
+	Recipient string
+	Message   string
+}
+
+func resolveGift(ctx context.Context, senderID int, req GiftRequest) (int, *string, error) {
+	recipient := strings.TrimSpace(req.Recipient)
+	if recipient == "" {
+		return 0, nil, errors.New("enter the recipient's email or username")
+	}
+
+	recipientID, _, _, err := repository.FindCustomer(ctx, db.Pool, recipient)
+	if err != nil {
+		return 0, nil, err
+	}
+	if recipientID == senderID {
+		return 0, nil, errors.New("you can't send a gift to yourself")
+	}
+
+	msg := strings.TrimSpace(req.Message)
+	if len(msg) > 500 {
+		return 0, nil, errors.New("gift message must be at most 500 characters")
+	}
+	if msg == "" {
+		return recipientID, nil, nil
+	}
+	return recipientID, &msg, nil
+}
+
+func ReceivedGifts(ctx context.Context, customerID int) ([]repository.Gift, error) {
+	return repository.GetReceivedGifts(ctx, db.Pool, customerID)
+}
+
+func OrderGift(ctx context.Context, orderID int) (*repository.Gift, error) {
+	return repository.GetGiftByOrderID(ctx, db.Pool, orderID)
+}
+
+// AcceptGift adds the gifted games to the recipient's library
+func AcceptGift(ctx context.Context, customerID, giftID int) error {
+	gift, err := repository.RespondToGift(ctx, db.Pool, customerID, giftID, true)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("%s accepted your gift: %s.", gift.RecipientName, gift.Titles)
+	return repository.CreateNotification(ctx, db.Pool, gift.SenderAuthID, msg)
+}
+
+// DeclineGift refunds the sender's payment
+func DeclineGift(ctx context.Context, customerID, giftID int) error {
+	gift, err := repository.RespondToGift(ctx, db.Pool, customerID, giftID, false)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("%s declined your gift (%s). Order %d has been refunded.", gift.RecipientName, gift.Titles, gift.OrderID)
+	return repository.CreateNotification(ctx, db.Pool, gift.SenderAuthID, msg)
+}
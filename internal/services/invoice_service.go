@@ -0,0 +1,180 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"GamesProject/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// seller details printed on invoices, from STORE_NAME, STORE_ADDRESS and STORE_TAX_ID
+func sellerLines() []string {
+	name := os.Getenv("STORE_NAME")
+	if name == "" {
+		name = "GamesProject Store"
+	}
+
+	lines := []string{"  " + name}
+	if addr := os.Getenv("STORE_ADDRESS"); addr != "" {
+		lines = append(lines, "  "+addr)
+	}
+	if taxID := os.Getenv("STORE_TAX_ID"); taxID != "" {
+		lines = append(lines, "  Tax ID: "+taxID)
+	}
+	return lines
+}
+
+// IssueInvoice creates the invoice of a paid order once; later calls return the stored copy
+func IssueInvoice(ctx context.Context, orderID int) (*repository.Invoice, error) {
+	inv, err := repository.GetInvoiceByOrderID(ctx, db.Pool, orderID)
+	if err != nil || inv != nil {
+		return inv, err
+	}
+
+	order, err := repository.GetOrderSummary(ctx, db.Pool, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	payment, method, err := repository.GetPaidPayment(ctx, db.Pool, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, errors.New("invoices are only issued for paid orders")
+	}
+
+	lines, err := repository.GetOrderLines(ctx, db.Pool, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	symbol, code := BaseCurrency(ctx).Symbol, BaseCurrencyCode()
+	if order.CurrencyCode != nil {
+		code = *order.CurrencyCode
+		if order.Symbol != nil {
+			symbol = *order.Symbol
+		}
+	}
+
+	return repository.CreateInvoice(ctx, db.Pool, orderID, order.CustomerID, func(number string, issuedAt time.Time) string {
+		return renderInvoice(number, issuedAt, order, lines, payment, method, symbol, code)
+	})
+}
+
+func renderInvoice(number string, issuedAt time.Time, order *repository.OrderSummary,
+	lines []repository.OrderLine, payment *repository.Payment, method, symbol, code string) string {
+
+	var b strings.Builder
+	rule := strings.Repeat("-", 72)
+
+	fmt.Fprintf(&b, "INVOICE %s\n", number)
+	fmt.Fprintf(&b, "Issued: %s\n\n", issuedAt.Format("2006-01-02 15:04"))
+
+	b.WriteString("Seller:\n")
+	for _, l := range sellerLines() {
+		b.WriteString(l + "\n")
+	}
+
+	b.WriteString("\nBill To:\n")
+	if order.FullName != nil && *order.FullName != "" {
+		fmt.Fprintf(&b, "  %s (%s)\n", *order.FullName, order.Username)
+	} else {
+		fmt.Fprintf(&b, "  %s\n", order.Username)
+	}
+	fmt.Fprintf(&b, "  %s\n", order.Email)
+	if order.Address != nil && *order.Address != "" {
+		fmt.Fprintf(&b, "  %s\n", *order.Address)
+	}
+
+	fmt.Fprintf(&b, "\nOrder #%d placed %s\n", order.OrderID, order.OrderDate.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "Currency: %s\n\n", code)
+
+	fmt.Fprintf(&b, "%-34s %4s %10s %9s %10s\n", "Item", "Qty", "Unit", "Tax", "Amount")
+	b.WriteString(rule + "\n")
+
+	var subtotal float64
+	for _, l := range lines {
+		amount := float64(l.Quantity) * l.UnitPrice
+		subtotal += amount
+		fmt.Fprintf(&b, "%-34.34s %4d %10.2f %9.2f %10.2f\n", l.Title, l.Quantity, l.UnitPrice, l.TaxAmount, amount)
+
+		detail := "by " + l.DeveloperName
+		if l.BundleTitle != nil {
+			detail += ", bundle: " + *l.BundleTitle
+		}
+		fmt.Fprintf(&b, "  %s\n", detail)
+	}
+	b.WriteString(rule + "\n")
+
+	if order.TaxInclusive {
+		fmt.Fprintf(&b, "%-50s %s%.2f\n", "Total", symbol, order.TotalPrice)
+		fmt.Fprintf(&b, "%-50s %s%.2f\n", fmt.Sprintf("Includes tax (%.2f%%)", order.TaxRate), symbol, order.TaxTotal)
+	} else {
+		fmt.Fprintf(&b, "%-50s %s%.2f\n", "Subtotal", symbol, roundMoney(subtotal))
+		fmt.Fprintf(&b, "%-50s %s%.2f\n", fmt.Sprintf("Tax (%.2f%%)", order.TaxRate), symbol, order.TaxTotal)
+		fmt.Fprintf(&b, "%-50s %s%.2f\n", "Total", symbol, order.TotalPrice)
+	}
+
+	fmt.Fprintf(&b, "\nPaid via %s", method)
+	if payment.PaidAt != nil {
+		fmt.Fprintf(&b, " on %s", payment.PaidAt.Format("2006-01-02 15:04"))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// CustomerInvoice returns the invoice of one of the customer's own orders
+func CustomerInvoice(ctx context.Context, customerID, orderID int) (*repository.Invoice, error) {
+	order, err := repository.GetOrderSummary(ctx, db.Pool, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.CustomerID != customerID {
+		return nil, errors.New("order not found")
+	}
+	return IssueInvoice(ctx, orderID)
+}
+
+func InvoiceText(inv *repository.Invoice) string {
+	return inv.Body
+}
+
+// InvoicePDF renders the stored text, so the PDF always matches what was issued
+func InvoicePDF(inv *repository.Invoice) []byte {
+	return utils.TextPDF(strings.Split(strings.TrimRight(inv.Body, "\n"), "\n"))
+}
+
+// SaveInvoice writes the invoice to INVOICE_DIR (default ./invoices) as "txt" or "pdf"
+func SaveInvoice(inv *repository.Invoice, format string) (string, error) {
+	dir := os.Getenv("INVOICE_DIR")
+	if dir == "" {
+		dir = "invoices"
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	var data []byte
+	switch format {
+	case "txt":
+		data = []byte(InvoiceText(inv))
+	case "pdf":
+		data = InvoicePDF(inv)
+	default:
+		return "", fmt.Errorf("unknown invoice format %q", format)
+	}
+
+	path := filepath.Join(dir, inv.InvoiceNumber+"."+format)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
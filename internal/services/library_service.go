@@ -0,0 +1,11 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+)
+
+func GetLibrary(ctx context.Context, customerID int) ([]repository.Entitlement, error) {
+	return repository.GetCustomerEntitlements(ctx, db.Pool, customerID)
+}
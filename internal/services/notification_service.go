@@ -0,0 +1,19 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+)
+
+func GetNotifications(ctx context.Context, authID int) ([]repository.Notification, error) {
+	return repository.GetNotifications(ctx, db.Pool, authID)
+}
+
+func UnreadNotificationCount(ctx context.Context, authID int) (int, error) {
+	return repository.CountUnreadNotifications(ctx, db.Pool, authID)
+}
+
+func MarkNotificationsRead(ctx context.Context, authID int) error {
+	return repository.MarkNotificationsRead(ctx, db.Pool, authID)
+}
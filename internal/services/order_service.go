@@ -4,8 +4,126 @@ import (
 	"GamesProject/internal/db"
 	"GamesProject/internal/repository"
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
 )
 
 func GetOrderHistory(ctx context.Context, customerID int) ([]repository.OrderHistoryItem, error) {
-	return repository.GetOrderHistory(ctx, db.Pool, customerID)
+	list, err := repository.GetOrderHistory(ctx, db.Pool, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	base := BaseCurrency(ctx)
+	for i := range list {
+		if list[i].CurrencySymbol == "" {
+			list[i].CurrencySymbol = base.Symbol
+		}
+	}
+	return list, nil
+}
+
+type PaymentAttempt struct {
+	repository.Payment
+	Method string
+	Logs   []repository.PaymentLog
+}
+
+// OrderDetail is everything known about one checked-out order
+type OrderDetail struct {
+	Summary      *repository.OrderSummary
+	Symbol       string
+	Lines        []repository.OrderLine
+	Payments     []PaymentAttempt
+	Entitlements []repository.Entitlement
+}
+
+// CustomerOrderDetail returns the detail of one of the customer's own orders
+func CustomerOrderDetail(ctx context.Context, customerID, orderID int) (*OrderDetail, error) {
+	d, err := GetOrderDetail(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Summary.CustomerID != customerID {
+		return nil, errors.New("order not found")
+	}
+	return d, nil
+}
+
+func GetOrderDetail(ctx context.Context, orderID int) (*OrderDetail, error) {
+	summary, err := repository.GetOrderSummary(ctx, db.Pool, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &OrderDetail{Summary: summary, Symbol: BaseCurrency(ctx).Symbol}
+	if summary.Symbol != nil {
+		d.Symbol = *summary.Symbol
+	}
+
+	if d.Lines, err = repository.GetOrderLines(ctx, db.Pool, orderID); err != nil {
+		return nil, err
+	}
+
+	methods, err := repository.GetPaymentMethods(ctx, db.Pool)
+	if err != nil {
+		return nil, err
+	}
+	names := map[int]string{}
+	for _, m := range methods {
+		names[m.PaymentMethodID] = m.Name
+	}
+
+	payments, err := repository.GetPaymentsByOrderID(ctx, db.Pool, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range payments {
+		logs, err := repository.GetPaymentLogs(ctx, db.Pool, p.PaymentID)
+		if err != nil {
+			return nil, err
+		}
+		d.Payments = append(d.Payments, PaymentAttempt{Payment: p, Method: names[p.PaymentMethodID], Logs: logs})
+	}
+
+	if d.Entitlements, err = repository.GetOrderEntitlements(ctx, db.Pool, orderID); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// CancelOrder cancels one of the customer's unpaid orders. Orders only hold
+// digital games, so there are no keys or stock to release.
+func CancelOrder(ctx context.Context, customerID, orderID int) error {
+	return repository.CancelOrder(ctx, db.Pool, customerID, orderID)
+}
+
+// OrderPaymentTimeout is how long a checked-out order may stay unpaid,
+// from ORDER_PAYMENT_TIMEOUT (e.g. "30m", default 30 minutes)
+func OrderPaymentTimeout() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("ORDER_PAYMENT_TIMEOUT")); err == nil && d > 0 {
+		return d
+	}
+	return 30 * time.Minute
+}
+
+// ExpireUnpaidOrders cancels orders left unpaid past the timeout and tells their customers
+func ExpireUnpaidOrders(ctx context.Context) error {
+	timeout := OrderPaymentTimeout()
+
+	expired, err := repository.CancelExpiredOrders(ctx, db.Pool, timeout)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range expired {
+		msg := fmt.Sprintf("Order %d was cancelled because it was not paid within %s.", e.OrderID, timeout)
+		if err := repository.CreateNotification(ctx, db.Pool, e.AuthID, msg); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -4,7 +4,7 @@ import (
 	"GamesProject/internal/db"
 	"GamesProject/internal/repository"
 	"context"
-	"errors"
+	"fmt"
 )
 
 // List available payment methods
@@ -12,32 +12,41 @@ func ListPaymentMethods(ctx context.Context) ([]repository.PaymentMethod, error)
 	return repository.GetPaymentMethods(ctx, db.Pool)
 }
 
-// StartPaymentForOrder creates a pending payment record for an order
+// StartPaymentForOrder creates a pending payment record for an order awaiting payment
 func StartPaymentForOrder(ctx context.Context, orderID, methodID int, amount float64) (int, error) {
-	// verify order exists and not deleted
-	// You may want to add a repository function to verify order; for now we assume order exists
+	order, err := repository.GetOrderSummary(ctx, db.Pool, orderID)
+	if err != nil {
+		return 0, err
+	}
+	if order.Status != "pending" {
+		return 0, fmt.Errorf("order is %s and cannot be paid", order.Status)
+	}
 	return repository.CreatePayment(ctx, db.Pool, orderID, methodID, amount)
 }
 
 // ConfirmPayment marks payment as Paid and returns error if fails
 func ConfirmPayment(ctx context.Context, paymentID int) error {
-	// Check payment exists
-	p, err := repository.GetPaymentByID(ctx, db.Pool, paymentID)
+	// the order may have been cancelled or expired since the payment started;
+	// it is claimed, paid and its games granted in one transaction
+	orderID, err := repository.ConfirmOrderPayment(ctx, db.Pool, paymentID)
 	if err != nil {
 		return err
 	}
 
-	if p.PaymentStatus == "Paid" {
-		return errors.New("payment already paid")
-	}
-
-	// Update status to Paid (and create log)
-	if err := repository.UpdatePaymentStatus(ctx, db.Pool, paymentID, "Paid"); err != nil {
+	gift, err := repository.GetGiftByOrderID(ctx, db.Pool, orderID)
+	if err != nil {
 		return err
 	}
+	if gift != nil {
+		msg := fmt.Sprintf("%s sent you a gift: %s. Accept or decline it under Gifts Received.", gift.SenderName, gift.Titles)
+		if err := repository.CreateNotification(ctx, db.Pool, gift.RecipientAuthID, msg); err != nil {
+			return err
+		}
+	}
 
-	// Optionally you can perform additional post-payment actions here
-	// e.g., send notification, grant license, etc.
+	// The payment stands even if this fails; the invoice is then issued
+	// the first time the customer opens it from order history
+	_, _ = IssueInvoice(ctx, orderID)
 
 	return nil
 }
@@ -57,5 +66,5 @@ func GetPaymentsForOrder(ctx context.Context, orderID int) ([]repository.Payment
 }
 
 func GetAllTransactions(ctx context.Context) ([]repository.AdminTransaction, error) {
-	return repository.GetAllTransactions(ctx, db.Pool)
+	return repository.GetAllTransactions(ctx, db.Pool, BaseCurrencyCode())
 }
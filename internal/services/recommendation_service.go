@@ -0,0 +1,22 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+)
+
+const recommendationLimit = 5
+
+// RebuildRecommendations is the batch job behind "You might like" and "Customers also bought"
+func RebuildRecommendations(ctx context.Context) error {
+	return repository.RebuildRecommendations(ctx, db.Pool)
+}
+
+func CustomersAlsoBought(ctx context.Context, gameID int) ([]repository.GameList, error) {
+	return repository.GetAlsoBought(ctx, db.Pool, gameID, recommendationLimit)
+}
+
+func YouMightLike(ctx context.Context, customerID int) ([]repository.GameList, error) {
+	return repository.GetCustomerRecommendations(ctx, db.Pool, customerID, recommendationLimit)
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func GameReviews(ctx context.Context, gameID int) ([]repository.Review, error) {
+	return repository.GetGameReviews(ctx, db.Pool, gameID)
+}
+
+// WriteReview posts or edits the customer's review; only owners who can play
+// the game may review it, so a pre-order waits for the release
+func WriteReview(ctx context.Context, customerID, gameID, rating int, body string) error {
+	if rating < 1 || rating > 5 {
+		return errors.New("rating must be between 1 and 5")
+	}
+
+	unlocked, err := repository.HasUnlockedGame(ctx, db.Pool, customerID, gameID)
+	if err != nil {
+		return err
+	}
+	if !unlocked {
+		return errors.New("you can only review games you own and that have been released")
+	}
+
+	return repository.UpsertReview(ctx, db.Pool, customerID, gameID, rating, strings.TrimSpace(body))
+}
+
+func DeleteReview(ctx context.Context, customerID, gameID int) error {
+	return repository.DeleteReview(ctx, db.Pool, customerID, gameID)
+}
+
+func MarkReviewHelpful(ctx context.Context, customerID, reviewID int) error {
+	return repository.MarkReviewHelpful(ctx, db.Pool, customerID, reviewID)
+}
+
+func ReplyToReview(ctx context.Context, devID, reviewID int, reply string) error {
+	reply = strings.TrimSpace(reply)
+	if reply == "" {
+		return errors.New("reply cannot be empty")
+	}
+	return repository.ReplyToReview(ctx, db.Pool, devID, reviewID, reply)
+}
+
+func ReviewsForModeration(ctx context.Context) ([]repository.Review, error) {
+	return repository.GetReviewsForModeration(ctx, db.Pool)
+}
+
+func HideReview(ctx context.Context, actorAuthID, reviewID int) error {
+	return audited(ctx, actorAuthID, "review.hide", "review", "reviews", reviewID, func(tx pgx.Tx) error {
+		return repository.SetReviewHidden(ctx, tx, reviewID, true)
+	})
+}
+
+func UnhideReview(ctx context.Context, actorAuthID, reviewID int) error {
+	return audited(ctx, actorAuthID, "review.unhide", "review", "reviews", reviewID, func(tx pgx.Tx) error {
+		return repository.SetReviewHidden(ctx, tx, reviewID, false)
+	})
+}
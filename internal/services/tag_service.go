@@ -0,0 +1,107 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var tagNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9 \-]{1,29}$`)
+
+// normalizeTag lower-cases and collapses spaces so "Pixel  Art" and "pixel art" are one tag
+func normalizeTag(name string) (string, error) {
+	name = strings.Join(strings.Fields(strings.ToLower(name)), " ")
+	if !tagNameRegex.MatchString(name) {
+		return "", errors.New("tags must be 2-30 characters of letters, digits, spaces or '-'")
+	}
+	return name, nil
+}
+
+// TagGame records a tag vote. Developers may only tag their own games.
+func TagGame(ctx context.Context, authID int, role string, devID, gameID int, name string) error {
+	name, err := normalizeTag(name)
+	if err != nil {
+		return err
+	}
+
+	if role == "developer" {
+		owned, err := repository.IsGameOwnedByDeveloper(ctx, db.Pool, devID, gameID)
+		if err != nil {
+			return err
+		}
+		if !owned {
+			return errors.New("permission denied: you can only tag your own games")
+		}
+	}
+
+	return repository.ApplyTag(ctx, db.Pool, authID, gameID, name)
+}
+
+func RemoveTagVote(ctx context.Context, authID, gameID, tagID int) error {
+	return repository.RemoveTagVote(ctx, db.Pool, authID, gameID, tagID)
+}
+
+func GameTags(ctx context.Context, gameID int) ([]repository.TagCount, error) {
+	return repository.GetGameTopTags(ctx, db.Pool, gameID, 20)
+}
+
+func SearchTags(ctx context.Context, term string) ([]repository.TagCount, error) {
+	return repository.SearchTags(ctx, db.Pool, strings.ToLower(strings.TrimSpace(term)))
+}
+
+func GamesByTag(ctx context.Context, tagID int) ([]repository.GameList, error) {
+	return repository.GetGamesByTag(ctx, db.Pool, tagID)
+}
+
+func AllTags(ctx context.Context) ([]repository.TagCount, error) {
+	return repository.GetAllTags(ctx, db.Pool)
+}
+
+func BlacklistTag(ctx context.Context, actorAuthID, tagID int) error {
+	return audited(ctx, actorAuthID, "tag.blacklist", "tag", "tags", tagID, func(tx pgx.Tx) error {
+		return repository.SetTagBlacklisted(ctx, tx, tagID, true)
+	})
+}
+
+func UnblacklistTag(ctx context.Context, actorAuthID, tagID int) error {
+	return audited(ctx, actorAuthID, "tag.unblacklist", "tag", "tags", tagID, func(tx pgx.Tx) error {
+		return repository.SetTagBlacklisted(ctx, tx, tagID, false)
+	})
+}
+
+// BlacklistTagName bans a tag name before anyone uses it
+func BlacklistTagName(ctx context.Context, actorAuthID int, name string) error {
+	name, err := normalizeTag(name)
+	if err != nil {
+		return err
+	}
+
+	return withAudit(ctx, actorAuthID, func(tx pgx.Tx) (*auditEntry, error) {
+		var before json.RawMessage
+		existing, err := repository.GetTagID(ctx, tx, name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != 0 {
+			if before, err = repository.AuditSnapshot(ctx, tx, "tags", existing); err != nil {
+				return nil, err
+			}
+		}
+
+		id, err := repository.BlacklistTagName(ctx, tx, name)
+		if err != nil {
+			return nil, err
+		}
+		after, err := repository.AuditSnapshot(ctx, tx, "tags", id)
+		if err != nil {
+			return nil, err
+		}
+		return &auditEntry{Action: "tag.blacklist", TargetType: "tag", TargetID: id, Before: before, After: after}, nil
+	})
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TaxInclusive reports whether listed prices already include tax.
+// Set TAX_PRICING_MODE=inclusive for VAT-style pricing; the default is exclusive,
+// where tax is added on top at checkout.
+func TaxInclusive() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("TAX_PRICING_MODE")), "inclusive")
+}
+
+// TaxQuote is the tax breakdown of a cart. Subtotal is the sum of the listed prices.
+type TaxQuote struct {
+	TaxName   string
+	Rate      float64
+	Inclusive bool
+	Subtotal  float64
+	Tax       float64
+	Total     float64
+	ItemTax   map[int]float64
+}
+
+// parseLocation reads "street, city, region, country" style addresses:
+// the last part is the country and the one before it the region
+func parseLocation(address string) (country, region string) {
+	var parts []string
+	for _, p := range strings.Split(address, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	if len(parts) > 0 {
+		country = parts[len(parts)-1]
+	}
+	if len(parts) > 1 {
+		region = parts[len(parts)-2]
+	}
+	return country, region
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// customerTaxRate returns nil when the customer has no address or the location isn't taxed
+func customerTaxRate(ctx context.Context, customerID int) (*repository.TaxRate, error) {
+	address, err := repository.GetCustomerAddress(ctx, db.Pool, customerID)
+	if err != nil || address == nil {
+		return nil, err
+	}
+
+	country, region := parseLocation(*address)
+	if country == "" {
+		return nil, nil
+	}
+	return repository.FindTaxRate(ctx, db.Pool, country, region)
+}
+
+// QuoteTax works out per-line tax for the customer's location and pricing mode
+func QuoteTax(ctx context.Context, customerID int, items []repository.CartItem) (*TaxQuote, error) {
+	rate, err := customerTaxRate(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return quoteTax(rate, TaxInclusive(), items), nil
+}
+
+// quoteTax rounds each line's tax on its own, so the lines add up to the total;
+// a nil rate quotes no tax
+func quoteTax(rate *repository.TaxRate, inclusive bool, items []repository.CartItem) *TaxQuote {
+	q := &TaxQuote{TaxName: "Tax", Inclusive: inclusive, ItemTax: map[int]float64{}}
+	if rate != nil {
+		q.TaxName = rate.TaxName
+		q.Rate = rate.RatePercent
+	}
+
+	for _, item := range items {
+		line := float64(item.Quantity) * item.PriceAtPurchase
+		q.Subtotal += line
+
+		var tax float64
+		if q.Inclusive {
+			tax = roundMoney(line - line/(1+q.Rate/100))
+		} else {
+			tax = roundMoney(line * q.Rate / 100)
+		}
+		q.ItemTax[item.OrderItemID] = tax
+		q.Tax += tax
+	}
+
+	q.Subtotal = roundMoney(q.Subtotal)
+	q.Tax = roundMoney(q.Tax)
+	q.Total = q.Subtotal
+	if !q.Inclusive {
+		q.Total = roundMoney(q.Subtotal + q.Tax)
+	}
+	return q
+}
+
+func BillingAddress(ctx context.Context, customerID int) (string, error) {
+	address, err := repository.GetCustomerAddress(ctx, db.Pool, customerID)
+	if err != nil || address == nil {
+		return "", err
+	}
+	return *address, nil
+}
+
+// SetBillingAddress requires at least "region, country" so tax can be worked out
+func SetBillingAddress(ctx context.Context, customerID int, address string) error {
+	address = strings.TrimSpace(address)
+	if country, _ := parseLocation(address); country == "" || !strings.Contains(address, ",") {
+		return errors.New("address must end with region and country, e.g. '1 Main St, Springfield, Oregon, USA'")
+	}
+	return repository.SetCustomerAddress(ctx, db.Pool, customerID, address)
+}
+
+func ListTaxRates(ctx context.Context) ([]repository.TaxRate, error) {
+	return repository.GetTaxRates(ctx, db.Pool)
+}
+
+// SaveTaxRate adds or updates a rate. An empty region sets the country-wide rate.
+func SaveTaxRate(ctx context.Context, actorAuthID int, country, region, name string, rate float64) error {
+	country = strings.TrimSpace(country)
+	region = strings.TrimSpace(region)
+	name = strings.TrimSpace(name)
+
+	if country == "" {
+		return errors.New("country is required")
+	}
+	if rate < 0 || rate > 100 {
+		return errors.New("rate must be between 0 and 100")
+	}
+	if name == "" {
+		name = "Tax"
+	}
+
+	var regionPtr *string
+	if region != "" {
+		regionPtr = &region
+	}
+
+	return withAudit(ctx, actorAuthID, func(tx pgx.Tx) (*auditEntry, error) {
+		var before json.RawMessage
+		existing, err := repository.GetTaxRateID(ctx, tx, country, regionPtr)
+		if err != nil {
+			return nil, err
+		}
+		if existing != 0 {
+			if before, err = repository.AuditSnapshot(ctx, tx, "tax_rates", existing); err != nil {
+				return nil, err
+			}
+		}
+
+		id, err := repository.UpsertTaxRate(ctx, tx, country, regionPtr, name, rate)
+		if err != nil {
+			return nil, err
+		}
+		after, err := repository.AuditSnapshot(ctx, tx, "tax_rates", id)
+		if err != nil {
+			return nil, err
+		}
+		return &auditEntry{Action: "tax_rate.save", TargetType: "tax_rate", TargetID: id, Before: before, After: after}, nil
+	})
+}
+
+func RemoveTaxRate(ctx context.Context, actorAuthID, taxRateID int) error {
+	return audited(ctx, actorAuthID, "tax_rate.remove", "tax_rate", "tax_rates", taxRateID, func(tx pgx.Tx) error {
+		return repository.RemoveTaxRate(ctx, tx, taxRateID)
+	})
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"GamesProject/internal/repository"
+	"testing"
+)
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		address         string
+		country, region string
+	}{
+		{"1 Main St, Springfield, Oregon, USA", "USA", "Oregon"},
+		{"Oregon, USA", "USA", "Oregon"},
+		{"  Bavaria ,Germany  ", "Germany", "Bavaria"},
+		{"Germany", "Germany", ""},
+		{"Berlin, , Germany,", "Germany", "Berlin"}, // empty parts are skipped
+		{"", "", ""},
+		{" , ", "", ""},
+	}
+
+	for _, tt := range tests {
+		country, region := parseLocation(tt.address)
+		if country != tt.country || region != tt.region {
+			t.Errorf("parseLocation(%q) = %q, %q; want %q, %q", tt.address, country, region, tt.country, tt.region)
+		}
+	}
+}
+
+func TestRoundMoney(t *testing.T) {
+	tests := []struct{ in, want float64 }{
+		{1.004, 1},
+		{1.006, 1.01},
+		{0.999, 1},
+		{19.97, 19.97},
+		{-2.345, -2.35},
+	}
+
+	for _, tt := range tests {
+		if got := roundMoney(tt.in); got != tt.want {
+			t.Errorf("roundMoney(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteTax(t *testing.T) {
+	items := []repository.CartItem{
+		{OrderItemID: 1, Quantity: 1, PriceAtPurchase: 9.99},
+		{OrderItemID: 2, Quantity: 2, PriceAtPurchase: 4.99},
+	}
+	vat := &repository.TaxRate{TaxName: "VAT", RatePercent: 19}
+	salesTax := &repository.TaxRate{TaxName: "Sales tax", RatePercent: 10}
+
+	tests := []struct {
+		name      string
+		rate      *repository.TaxRate
+		inclusive bool
+		want      TaxQuote
+	}{
+		{
+			name: "exclusive adds tax on top, rounded per line",
+			rate: salesTax,
+			want: TaxQuote{
+				TaxName: "Sales tax", Rate: 10,
+				Subtotal: 19.97, Tax: 2, Total: 21.97,
+				ItemTax: map[int]float64{1: 1, 2: 1},
+			},
+		},
+		{
+			name: "inclusive takes tax out of the listed price",
+			rate: vat, inclusive: true,
+			want: TaxQuote{
+				TaxName: "VAT", Rate: 19, Inclusive: true,
+				Subtotal: 19.97, Tax: 3.19, Total: 19.97,
+				ItemTax: map[int]float64{1: 1.60, 2: 1.59},
+			},
+		},
+		{
+			name: "no rate for the location",
+			want: TaxQuote{
+				TaxName:  "Tax",
+				Subtotal: 19.97, Tax: 0, Total: 19.97,
+				ItemTax: map[int]float64{1: 0, 2: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got := quoteTax(tt.rate, tt.inclusive, items)
+		if got.TaxName != tt.want.TaxName || got.Rate != tt.want.Rate || got.Inclusive != tt.want.Inclusive ||
+			got.Subtotal != tt.want.Subtotal || got.Tax != tt.want.Tax || got.Total != tt.want.Total {
+			t.Errorf("%s: got %+v, want %+v", tt.name, *got, tt.want)
+		}
+		for id, tax := range tt.want.ItemTax {
+			if got.ItemTax[id] != tax {
+				t.Errorf("%s: item %d tax = %v, want %v", tt.name, id, got.ItemTax[id], tax)
+			}
+		}
+	}
+
+	if q := quoteTax(vat, false, nil); q.Subtotal != 0 || q.Tax != 0 || q.Total != 0 {
+		t.Errorf("empty cart: got %+v, want zeros", *q)
+	}
+}
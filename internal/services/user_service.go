@@ -1,21 +1,154 @@
 package services
 
 import (
+	"GamesProject/internal/auth"
 	"GamesProject/internal/db"
 	"GamesProject/internal/repository"
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
 func GetAllUsers(ctx context.Context) ([]repository.UserDetail, error) {
 	return repository.GetAllUsers(ctx, db.Pool)
 }
 
-func BanUser(ctx context.Context, authID int) error {
-	return repository.SoftDeleteUser(ctx, db.Pool, authID)
+/*
+BanUser suspends any account but the caller's own. expiresAt nil means until
+lifted by hand; hideGames only applies to developers.
+*/
+func BanUser(ctx context.Context, actorAuthID, authID int, reason string, expiresAt *time.Time, hideGames bool) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("a reason is required")
+	}
+	if len(reason) > 500 {
+		return errors.New("reason must be at most 500 characters")
+	}
+	if actorAuthID == authID {
+		return errors.New("you cannot suspend your own account")
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return errors.New("expiry must be in the future")
+	}
+
+	account, err := repository.GetAccountByAuthID(ctx, db.Pool, authID)
+	if err != nil {
+		return err
+	}
+	if account.Role != "developer" {
+		hideGames = false
+	}
+
+	return withAudit(ctx, actorAuthID, func(tx pgx.Tx) (*auditEntry, error) {
+		before, err := repository.AuditSnapshot(ctx, tx, "userauth", authID)
+		if err != nil {
+			return nil, err
+		}
+
+		suspension, err := repository.SuspendAccount(ctx, tx, authID, actorAuthID, reason, expiresAt, hideGames)
+		if err != nil {
+			return nil, err
+		}
+
+		after, err := repository.AuditSnapshot(ctx, tx, "userauth", authID)
+		if err != nil {
+			return nil, err
+		}
+		return &auditEntry{
+			Action: "user.ban", TargetType: "account", TargetID: authID,
+			Before: before,
+			After:  map[string]any{"account": after, "suspension": suspension},
+		}, nil
+	})
+}
+
+func UnbanUser(ctx context.Context, actorAuthID, authID int) error {
+	return audited(ctx, actorAuthID, "user.unban", "account", "userauth", authID, func(tx pgx.Tx) error {
+		return repository.LiftSuspension(ctx, tx, authID, actorAuthID)
+	})
+}
+
+func ActiveSuspension(ctx context.Context, authID int) (*repository.Suspension, error) {
+	return repository.GetActiveSuspension(ctx, db.Pool, authID)
+}
+
+func SuspensionHistory(ctx context.Context, authID int) ([]repository.Suspension, error) {
+	return repository.GetSuspensionHistory(ctx, db.Pool, authID)
+}
+
+// LiftExpiredSuspensions is run by the scheduler; the system is recorded as the actor.
+// One account failing doesn't hold up the rest: every failure is reported together.
+func LiftExpiredSuspensions(ctx context.Context) error {
+	ids, err := repository.GetExpiredSuspensions(ctx, db.Pool)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, authID := range ids {
+		err := audited(ctx, 0, "suspension.expire", "account", "userauth", authID, func(tx pgx.Tx) error {
+			return repository.LiftSuspension(ctx, tx, authID, 0)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("lift suspension of account %d: %w", authID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RegisterDeveloper creates a developer account on an admin's behalf; the
+// account and its audit entry are written in one transaction
+func RegisterDeveloper(ctx context.Context, actorAuthID int, email, password, devName string) error {
+	return auth.RegisterForDeveloper(ctx, email, password, devName, func(tx pgx.Tx, authID int) error {
+		after, err := repository.AuditSnapshot(ctx, tx, "developers", authID)
+		if err != nil {
+			return err
+		}
+		return repository.WriteAuditLog(ctx, tx, actorAuthID, "developer.register", "account", authID, nil, after)
+	})
 }
 
-func UnbanUser(ctx context.Context, authID int) error {
-	return repository.RestoreUser(ctx, db.Pool, authID)
+// ResetUserTOTP lets an admin remove someone's 2FA, e.g. after a lost phone. It is audited.
+func ResetUserTOTP(ctx context.Context, actorAuthID, authID int) error {
+	return withAudit(ctx, actorAuthID, func(tx pgx.Tx) (*auditEntry, error) {
+		removed, err := repository.DeleteTOTP(ctx, tx, authID)
+		if err != nil {
+			return nil, err
+		}
+		if !removed {
+			return nil, errors.New("this account has no two-factor authentication set up")
+		}
+
+		return &auditEntry{
+			Action: "totp.reset", TargetType: "account", TargetID: authID,
+			Before: map[string]any{"totp_enabled": true},
+			After:  map[string]any{"totp_enabled": false},
+		}, nil
+	})
+}
+
+// GetLoginHistory returns the account's 20 most recent login attempts
+func GetLoginHistory(ctx context.Context, authID int) ([]repository.LoginAttempt, error) {
+	return repository.GetLoginAttempts(ctx, db.Pool, authID, 20)
+}
+
+func UnlockAccount(ctx context.Context, actorAuthID, authID int) error {
+	a, err := repository.GetAccountByAuthID(ctx, db.Pool, authID)
+	if err != nil {
+		return err
+	}
+	if a.LockedUntil == nil || a.LockedUntil.Before(time.Now()) {
+		return errors.New("account is not locked")
+	}
+
+	return audited(ctx, actorAuthID, "account.unlock", "account", "userauth", authID, func(tx pgx.Tx) error {
+		return repository.UnlockAccount(ctx, tx, authID)
+	})
 }
 
 func GetUserByID(ctx context.Context, authID int) (*repository.UserDetail, error) {
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"GamesProject/internal/db"
+	"GamesProject/internal/repository"
+	"context"
+)
+
+func AddToWishlist(ctx context.Context, customerID, gameID int) error {
+	// make sure the game exists and is still listed
+	if _, err := repository.GetGamePrice(ctx, db.Pool, gameID); err != nil {
+		return err
+	}
+	return repository.AddToWishlist(ctx, db.Pool, customerID, gameID)
+}
+
+func RemoveFromWishlist(ctx context.Context, customerID, gameID int) error {
+	return repository.RemoveFromWishlist(ctx, db.Pool, customerID, gameID)
+}
+
+func IsInWishlist(ctx context.Context, customerID, gameID int) (bool, error) {
+	return repository.IsInWishlist(ctx, db.Pool, customerID, gameID)
+}
+
+func GetWishlist(ctx context.Context, customerID int) ([]repository.WishlistItem, error) {
+	return repository.GetWishlist(ctx, db.Pool, customerID)
+}
+
+// MoveWishlistToCart adds the game to the cart and drops it from the wishlist
+func MoveWishlistToCart(ctx context.Context, customerID, gameID int) error {
+	if err := AddToCart(ctx, customerID, gameID, 1); err != nil {
+		return err
+	}
+	return repository.RemoveFromWishlist(ctx, db.Pool, customerID, gameID)
+}
+
+// notifyWishlists sends msg(title) to everyone wishlisting the game,
+// or any of the developer's games when gameID is nil
+func notifyWishlists(ctx context.Context, gameID *int, devID int, msg func(title string) string) error {
+	watchers, err := repository.GetWishlistWatchers(ctx, db.Pool, gameID, devID)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range watchers {
+		if err := repository.CreateNotification(ctx, db.Pool, w.AuthID, msg(w.Title)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -244,6 +244,23 @@ func ReadDate(prompt string) string {
 	}
 }
 
+// ReadDateTime keeps asking until the user enters "YYYY-MM-DD HH:MM" (local time)
+func ReadDateTime(prompt string) time.Time {
+	for {
+		fmt.Print(prompt)
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+
+		t, err := time.ParseInLocation("2006-01-02 15:04", input, time.Local)
+		if err != nil {
+			fmt.Println("Invalid format! Use YYYY-MM-DD HH:MM.")
+			continue
+		}
+
+		return t
+	}
+}
+
 type PageInput struct {
 	Command string // "<" or ">"
 	ID      int    // product ID (if any)
@@ -273,6 +290,11 @@ func ReadPagingInput(prompt string) PageInput {
 
 var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
 
+// ValidEmail applies the same format check as ReadEmail
+func ValidEmail(email string) bool {
+	return emailRegex.MatchString(email)
+}
+
 func ReadEmail(prompt string) string {
 	scanner := bufio.NewScanner(os.Stdin)
 
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+)
+
+const (
+	pdfLinesPerPage = 60
+	pdfFontSize     = 10
+	pdfLeading      = 12
+)
+
+// winAnsi maps the few non-Latin-1 characters we print (currency symbols) to
+// their WinAnsiEncoding byte; other runes above 0xFF become '?'
+var winAnsi = map[rune]byte{
+	'€': 0x80,
+	'–': 0x96,
+	'—': 0x97,
+}
+
+func pdfEscape(line string) []byte {
+	var out []byte
+	for _, r := range line {
+		var b byte
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			out = append(out, '\\')
+			b = byte(r)
+		case r < 0x100:
+			b = byte(r)
+		default:
+			if w, ok := winAnsi[r]; ok {
+				b = w
+			} else {
+				b = '?'
+			}
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// TextPDF renders plain text lines as a monospaced A4 PDF, so column layouts
+// from the text version line up the same way
+func TextPDF(lines []string) []byte {
+	var pages [][]string
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	var buf bytes.Buffer
+	var offsets []int
+
+	// objects 1-3 are catalog, page tree and font; each page then takes
+	// two objects: the page and its content stream
+	obj := func(body []byte) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+		buf.Write(body)
+		buf.WriteString("\nendobj\n")
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	kids := ""
+	for i := range pages {
+		kids += fmt.Sprintf("%d 0 R ", 4+i*2)
+	}
+
+	obj([]byte("<< /Type /Catalog /Pages 2 0 R >>"))
+	obj([]byte(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, len(pages))))
+	obj([]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>"))
+
+	for i, page := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL 40 800 Td\n", pdfFontSize, pdfLeading)
+		for _, line := range page {
+			content.WriteByte('(')
+			content.Write(pdfEscape(line))
+			content.WriteString(") Tj T*\n")
+		}
+		content.WriteString("ET")
+
+		obj([]byte(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			5+i*2)))
+
+		stream := fmt.Sprintf("<< /Length %d >>\nstream\n", content.Len())
+		obj(append(append([]byte(stream), content.Bytes()...), []byte("\nendstream")...))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
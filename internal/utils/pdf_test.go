@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPDFEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []byte
+	}{
+		{"plain", []byte("plain")},
+		{"Total (incl. tax)", []byte(`Total \(incl. tax\)`)},
+		{`C:\invoices`, []byte(`C:\\invoices`)},
+		{"))((", []byte(`\)\)\(\(`)},
+		{"café", []byte("caf\xe9")},
+		{"€9.99 – net", []byte("\x809.99 \x96 net")},
+		{"¥100 ₩500", []byte("\xa5100 ?500")},
+	}
+
+	for _, tt := range tests {
+		if got := pdfEscape(tt.in); !bytes.Equal(got, tt.want) {
+			t.Errorf("pdfEscape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// checkXref verifies that startxref points at the xref table and that every
+// entry in it points at the start of its object
+func checkXref(t *testing.T, pdf []byte) (objects int) {
+	t.Helper()
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("no startxref trailer at the end")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	lines := strings.Split(string(pdf[xref:]), "\n")
+	var first, count int
+	if _, err := fmt.Sscanf(lines[1], "%d %d", &first, &count); err != nil || first != 0 {
+		t.Fatalf("bad xref subsection header %q", lines[1])
+	}
+	if lines[2] != "0000000000 65535 f " {
+		t.Errorf("object 0 entry = %q", lines[2])
+	}
+
+	for n := 1; n < count; n++ {
+		entry := lines[2+n]
+		if len(entry) != 19 || !strings.HasSuffix(entry, " 00000 n ") {
+			t.Fatalf("xref entry %d = %q, want 20 bytes with EOL", n, entry)
+		}
+		off, _ := strconv.Atoi(entry[:10])
+		if want := fmt.Sprintf("%d 0 obj\n", n); !bytes.HasPrefix(pdf[off:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q, want %q", n, pdf[off:min(off+12, len(pdf))], want)
+		}
+	}
+
+	if !bytes.Contains(pdf, []byte(fmt.Sprintf("trailer\n<< /Size %d ", count))) {
+		t.Errorf("trailer /Size does not match the %d xref entries", count)
+	}
+	return count - 1
+}
+
+func TestTextPDFStructure(t *testing.T) {
+	lines := func(n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = fmt.Sprintf("line %d", i+1)
+		}
+		return out
+	}
+
+	tests := []struct {
+		lines int
+		pages int
+	}{
+		{0, 1},
+		{1, 1},
+		{60, 1},
+		{61, 2},
+		{120, 2},
+		{121, 3},
+	}
+
+	for _, tt := range tests {
+		pdf := TextPDF(lines(tt.lines))
+
+		if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) {
+			t.Fatalf("%d lines: missing header", tt.lines)
+		}
+		objects := checkXref(t, pdf)
+		if want := 3 + 2*tt.pages; objects != want {
+			t.Errorf("%d lines: %d objects, want %d", tt.lines, objects, want)
+		}
+		if !bytes.Contains(pdf, []byte(fmt.Sprintf("/Count %d >>", tt.pages))) {
+			t.Errorf("%d lines: page tree doesn't count %d pages", tt.lines, tt.pages)
+		}
+
+		// every line lands on exactly one page, in order
+		shown := regexp.MustCompile(`\(line (\d+)\) Tj`).FindAllSubmatch(pdf, -1)
+		if len(shown) != tt.lines {
+			t.Errorf("%d lines: %d shown", tt.lines, len(shown))
+		}
+		for i, s := range shown {
+			if string(s[1]) != strconv.Itoa(i+1) {
+				t.Errorf("%d lines: line %d shown as %s", tt.lines, i+1, s[1])
+				break
+			}
+		}
+
+		// each stream's /Length is the byte count between stream and endstream
+		for _, m := range regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)\nendstream`).FindAllSubmatch(pdf, -1) {
+			if n, _ := strconv.Atoi(string(m[1])); n != len(m[2]) {
+				t.Errorf("%d lines: stream /Length %d, actual %d", tt.lines, n, len(m[2]))
+			}
+		}
+	}
+}
+
+func TestTextPDFEscapesText(t *testing.T) {
+	pdf := TextPDF([]string{`Refund (partial) for C:\games`})
+	if !bytes.Contains(pdf, []byte(`(Refund \(partial\) for C:\\games) Tj`)) {
+		t.Errorf("line not escaped in the content stream:\n%s", pdf)
+	}
+	checkXref(t, pdf)
+}
@@ -0,0 +1,77 @@
+-- Pre-orders and entitlements for databases created before them; ddl.sql
+-- already has the schema. Games already out are marked released, and every
+-- line of every paid order becomes an entitlement, so customers keep their
+-- library, can buy DLC for games they own and can review them.
+begin;
+
+alter table public.games
+add column if not exists released_at timestamp without time zone null;
+
+create table if not exists public.entitlements (
+  entitlementid serial not null,
+  customerid integer not null,
+  gameid integer not null,
+  orderitemid integer not null,
+  unlocked_at timestamp without time zone null,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  deleted_at timestamp without time zone null,
+  constraint entitlements_pkey primary key (entitlementid),
+  constraint entitlements_orderitemid_key unique (orderitemid),
+  constraint entitlements_customerid_fkey foreign KEY (customerid) references customers (customerid),
+  constraint entitlements_gameid_fkey foreign KEY (gameid) references games (gameid),
+  constraint entitlements_orderitemid_fkey foreign KEY (orderitemid) references orderitems (orderitemid)
+) TABLESPACE pg_default;
+
+create table if not exists public.notifications (
+  notificationid serial not null,
+  authid integer not null,
+  message text not null,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  read_at timestamp without time zone null,
+  constraint notifications_pkey primary key (notificationid),
+  constraint notifications_authid_fkey foreign KEY (authid) references userauth (authid)
+) TABLESPACE pg_default;
+
+-- same rule as the release job: no date, or a date that has come, is released
+update public.games
+set
+  released_at = coalesce(releasedate::timestamp, created_at, now())
+where
+  released_at is null
+  and (
+    releasedate is null
+    or releasedate <= current_date
+  );
+
+-- a game bought before its release date stays locked until the job releases it
+insert into
+  public.entitlements (customerid, gameid, orderitemid, unlocked_at, created_at)
+select
+  o.customerid,
+  oi.gameid,
+  oi.orderitemid,
+  case
+    when g.released_at is not null then paid.paidat
+  end,
+  paid.paidat
+from
+  public.orderitems oi
+  join public.orders o on o.orderid = oi.orderid
+  join public.games g on g.gameid = oi.gameid
+  join lateral (
+    select
+      coalesce(min(p.paidat), o.orderdate, now()) as paidat
+    from
+      public.payments p
+    where
+      p.orderid = o.orderid
+      and p.paymentstatus = 'Paid'
+    having
+      count(*) > 0
+  ) paid on true
+where
+  oi.deleted_at is null
+  and o.deleted_at is null
+on conflict (orderitemid) do nothing;
+
+commit;
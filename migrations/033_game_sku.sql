@@ -0,0 +1,37 @@
+-- Game SKUs for databases created before them; ddl.sql already has the schema.
+-- Existing games get the same default SKU the trigger gives new ones.
+begin;
+
+alter table public.games
+add column if not exists sku character varying(64) null;
+
+-- an earlier version enforced this as a table constraint, which also blocked
+-- reusing a removed game's SKU
+alter table public.games
+drop constraint if exists games_developerid_sku_key;
+
+create or replace function public.games_default_sku () RETURNS trigger LANGUAGE plpgsql as $$
+begin
+  if new.sku is null then
+    new.sku := 'GAME-' || new.gameid;
+  end if;
+  return new;
+end;
+$$;
+
+drop trigger if exists games_default_sku on public.games;
+
+create trigger games_default_sku BEFORE insert on public.games for EACH row
+execute FUNCTION public.games_default_sku ();
+
+update public.games
+set
+  sku = 'GAME-' || gameid
+where
+  sku is null;
+
+create unique index if not exists games_developerid_sku_key on public.games using btree (developerid, sku) TABLESPACE pg_default
+where
+  (deleted_at is null);
+
+commit;
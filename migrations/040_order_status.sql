@@ -0,0 +1,75 @@
+-- Order status for databases created before it existed; ddl.sql already has it.
+-- Existing orders are classified from their payments: before this, an order
+-- with totalprice = 0 was a cart and any other order had been checked out.
+begin;
+
+alter table public.orders
+add column if not exists status character varying(20) not null default 'cart'::character varying,
+add column if not exists checkedout_at timestamp without time zone null,
+add column if not exists cancelled_at timestamp without time zone null;
+
+alter table public.orders
+drop constraint if exists orders_status_check,
+add constraint orders_status_check check (
+  (
+    (status)::text = any (
+      (
+        array[
+          'cart'::character varying,
+          'pending'::character varying,
+          'paid'::character varying,
+          'cancelled'::character varying
+        ]
+      )::text[]
+    )
+  )
+);
+
+-- paid: a payment went through
+update public.orders o
+set
+  status = 'paid',
+  checkedout_at = coalesce(o.orderdate, o.created_at)
+where
+  exists (
+    select 1
+    from public.payments p
+    where
+      p.orderid = o.orderid
+      and p.paymentstatus = 'Paid'
+  );
+
+-- checked out but unpaid: still payable within the default 30 minute
+-- timeout, otherwise cancelled without notifying anyone about old orders
+update public.orders o
+set
+  status = case
+    when coalesce(o.orderdate, o.created_at) >= now() - interval '30 minutes' then 'pending'
+    else 'cancelled'
+  end,
+  checkedout_at = coalesce(o.orderdate, o.created_at),
+  cancelled_at = case
+    when coalesce(o.orderdate, o.created_at) >= now() - interval '30 minutes' then null
+    else now()
+  end
+where
+  o.status = 'cart'
+  and coalesce(o.totalprice, 0) <> 0;
+
+-- empty orders: only each customer's latest stays their cart
+update public.orders o
+set
+  status = 'cancelled',
+  cancelled_at = now()
+where
+  o.status = 'cart'
+  and o.orderid < (
+    select max(c.orderid)
+    from public.orders c
+    where
+      c.customerid = o.customerid
+      and c.status = 'cart'
+      and c.deleted_at is null
+  );
+
+commit;
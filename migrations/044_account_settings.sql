@@ -0,0 +1,48 @@
+-- My Account for databases created before it; ddl.sql already has the schema.
+-- Usernames become unique regardless of case, so existing names that only
+-- differ in case are told apart first.
+begin;
+
+create table if not exists public.auth_tokens (
+  tokenid serial not null,
+  authid integer not null,
+  purpose character varying(20) not null,
+  tokenhash character(64) not null,
+  newemail character varying(150) null,
+  created_at timestamp without time zone null default CURRENT_TIMESTAMP,
+  expires_at timestamp without time zone not null,
+  used_at timestamp without time zone null,
+  constraint auth_tokens_pkey primary key (tokenid),
+  constraint auth_tokens_authid_fkey foreign KEY (authid) references userauth (authid),
+  constraint auth_tokens_purpose_check check (
+    (
+      (purpose)::text = any (
+        (
+          array[
+            'email_change'::character varying
+          ]
+        )::text[]
+      )
+    )
+  )
+) TABLESPACE pg_default;
+
+create index if not exists auth_tokens_authid_purpose_idx on public.auth_tokens using btree (authid, purpose) TABLESPACE pg_default;
+
+-- the oldest account keeps the name, the others get their customer id
+-- appended and can pick a new one under My Account
+update public.customers c
+set
+  username = left(c.username, 19 - length(c.customerid::text)) || '_' || c.customerid
+where
+  exists (
+    select 1
+    from public.customers o
+    where
+      lower(o.username) = lower(c.username)
+      and o.customerid < c.customerid
+  );
+
+create unique index if not exists customers_username_key on public.customers using btree (lower((username)::text)) TABLESPACE pg_default;
+
+commit;
@@ -0,0 +1,47 @@
+-- Email verification and reset codes for databases created before them;
+-- ddl.sql already has the schema.
+begin;
+
+alter table public.auth_tokens
+add column if not exists attempts integer not null default 0;
+
+alter table public.auth_tokens
+drop constraint if exists auth_tokens_purpose_check,
+add constraint auth_tokens_purpose_check check (
+  (
+    (purpose)::text = any (
+      (
+        array[
+          'email_change'::character varying,
+          'verify_email'::character varying,
+          'password_reset'::character varying
+        ]
+      )::text[]
+    )
+  )
+);
+
+-- accounts created before verification existed count as verified, so their
+-- owners aren't locked out. Only done when the column is added: running this
+-- again must not verify accounts that signed up since.
+do $$
+begin
+  if not exists (
+    select 1
+    from information_schema.columns
+    where
+      table_schema = 'public'
+      and table_name = 'userauth'
+      and column_name = 'email_verified_at'
+  ) then
+    alter table public.userauth
+    add column email_verified_at timestamp without time zone null;
+
+    update public.userauth
+    set
+      email_verified_at = coalesce(created_at, now());
+  end if;
+end;
+$$;
+
+commit;
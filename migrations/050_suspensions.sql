@@ -0,0 +1,52 @@
+-- Suspensions for databases created before them; ddl.sql already has the schema.
+-- Games are hidden from the store with hidden_at, leaving deleted_at for games
+-- that were actually removed.
+begin;
+
+create table if not exists public.suspensions (
+  suspensionid serial not null,
+  authid integer not null,
+  reason character varying(500) not null,
+  suspendedby integer null,
+  created_at timestamp without time zone not null default CURRENT_TIMESTAMP,
+  expires_at timestamp without time zone null,
+  hidegames boolean not null default false,
+  lifted_at timestamp without time zone null,
+  liftedby integer null,
+  constraint suspensions_pkey primary key (suspensionid),
+  constraint suspensions_authid_fkey foreign KEY (authid) references userauth (authid),
+  constraint suspensions_suspendedby_fkey foreign KEY (suspendedby) references userauth (authid),
+  constraint suspensions_liftedby_fkey foreign KEY (liftedby) references userauth (authid)
+) TABLESPACE pg_default;
+
+create unique index if not exists suspensions_active_key on public.suspensions using btree (authid) TABLESPACE pg_default
+where
+  (lifted_at is null);
+
+create table if not exists public.suspension_games (
+  suspensionid integer not null,
+  gameid integer not null,
+  constraint suspension_games_pkey primary key (suspensionid, gameid),
+  constraint suspension_games_suspensionid_fkey foreign KEY (suspensionid) references suspensions (suspensionid),
+  constraint suspension_games_gameid_fkey foreign KEY (gameid) references games (gameid)
+) TABLESPACE pg_default;
+
+alter table public.games
+add column if not exists hidden_at timestamp without time zone null;
+
+-- an earlier version hid a suspended developer's games by setting deleted_at;
+-- those still under an active suspension move over to hidden_at
+update public.games g
+set
+  hidden_at = g.deleted_at,
+  deleted_at = null
+from
+  public.suspension_games sg
+  join public.suspensions s on s.suspensionid = sg.suspensionid
+where
+  sg.gameid = g.gameid
+  and s.lifted_at is null
+  and g.deleted_at is not null
+  and g.hidden_at is null;
+
+commit;